@@ -1,13 +1,17 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 
 	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/config"
 	"github.com/velocitykode/velocity-cli/internal/ui"
 )
 
@@ -16,13 +20,87 @@ var (
 	buildOS     string
 	buildArch   string
 	buildTags   string
+	buildAssets bool
+
+	buildTargets []string
+	buildJobs    int
+	buildDist    string
+
+	buildCgo string
+	buildCC  []string
+
+	buildImage string
+
+	buildReport  bool
+	buildCompare string
+
+	buildEnvFiles []string
 )
 
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build the application for production",
-	Long:  `Build the Velocity application for production deployment.`,
-	RunE:  runBuild,
+	Long: `Build the Velocity application for production deployment.
+
+The build runs with the environment from .env, .env.<APP_ENV> and .env.local
+(APP_ENV defaults to production), then any --env-file. Keys declared in
+.env.example that are not set are reported as warnings.
+
+Binaries are built with -trimpath and stripped of debug information. The
+version (git describe), commit and build time are stamped into package main
+variables, which apps can declare to report them:
+
+  var (
+      version = "dev"
+      commit  = ""
+      date    = ""
+  )
+
+SOURCE_DATE_EPOCH sets the build time for reproducible builds.
+
+With --assets the frontend is built first (the build script in package.json,
+run with bun or npm) and its output directory is embedded in the binary.
+When the binary runs where the directory is missing, it is written out for
+the app's static file server. Both can be configured in velocity.yaml:
+
+  build:
+    frontend: bun run build
+    assets: public/build
+
+A manifest with the binary's sha256, size, build flags and Go version is
+written next to it as <binary>.manifest.json.
+
+--targets builds several platforms at once, --jobs at a time, into --dist:
+
+  velocity build --targets linux/amd64,linux/arm64,darwin/arm64,windows/amd64
+
+Each binary is named <app>_<os>_<arch> and packaged as a .tar.gz, or a .zip
+for Windows, with sha256 sums of the archives in checksums.txt. --output
+sets the app name.
+
+Dependencies that need cgo, such as the SQLite driver, are found in the
+package graph. With --cgo auto (the default) they switch cgo on when a C
+compiler is available for the target: the host's for native builds, or one
+configured for cross builds. Otherwise, and with --cgo off, the build warns
+that the binary will fail where they are used. Cross compilers are set per
+target with --cc or in velocity.yaml:
+
+  build:
+    cc:
+      linux/arm64: aarch64-linux-gnu-gcc
+      windows/amd64: x86_64-w64-mingw32-gcc
+
+--report breaks the binary's size down by package and module, from the
+symbols of an unstripped copy linked alongside it, and shows the size of
+the embedded assets. It compares the binary with the previous manifest, or
+the one given with --compare, and flags growth over 5% as a regression.
+Package sizes are recorded in the manifest for the next comparison.
+
+--image builds a Docker image with a local docker build instead, and prints
+its size. A project without a Dockerfile gets one from make:dockerfile:
+
+  velocity build --image myapp:1.0.0`,
+	RunE: runBuild,
 }
 
 func init() {
@@ -30,47 +108,272 @@ func init() {
 	buildCmd.Flags().StringVar(&buildOS, "os", runtime.GOOS, "Target operating system")
 	buildCmd.Flags().StringVar(&buildArch, "arch", runtime.GOARCH, "Target architecture")
 	buildCmd.Flags().StringVar(&buildTags, "tags", "", "Build tags")
+	buildCmd.Flags().StringSliceVar(&buildTargets, "targets", nil, "Build for several os/arch targets and package each, e.g. linux/amd64,darwin/arm64")
+	buildCmd.Flags().IntVarP(&buildJobs, "jobs", "j", runtime.NumCPU(), "Targets to build at once with --targets")
+	buildCmd.Flags().StringVar(&buildDist, "dist", "dist", "Directory for --targets binaries, archives and checksums")
+	buildCmd.Flags().StringVar(&buildCgo, "cgo", cgoAuto, "Build with cgo: auto, on or off")
+	buildCmd.Flags().StringSliceVar(&buildCC, "cc", nil, "C compiler for cgo builds of a target, as os/arch=compiler (repeatable)")
+	buildCmd.Flags().StringVar(&buildImage, "image", "", "Build a Docker image tagged name:tag with docker build")
+	buildCmd.Flags().BoolVar(&buildAssets, "assets", false, "Build the frontend and embed its output in the binary")
+	buildCmd.Flags().StringSliceVar(&buildEnvFiles, "env-file", nil, "Extra env file to load after .env, .env.<env> and .env.local (repeatable)")
+	buildCmd.Flags().BoolVar(&buildReport, "report", false, "Print a size breakdown of the binary by package and module")
+	buildCmd.Flags().StringVar(&buildCompare, "compare", "", "Manifest to compare the --report with (default: the previous build's)")
+	buildCmd.MarkFlagsMutuallyExclusive("image", "targets")
+	buildCmd.MarkFlagsMutuallyExclusive("report", "targets")
+	buildCmd.MarkFlagsMutuallyExclusive("report", "image")
 }
 
 func runBuild(cmd *cobra.Command, args []string) error {
 	ui.Header("build")
+	if err := validateCgoMode(buildCgo); err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	if buildImage != "" {
+		// Images are linux; an explicit --arch picks their platform
+		platform := ""
+		if cmd != nil && cmd.Flags().Changed("arch") {
+			platform = "linux/" + buildArch
+		}
+		return runImageBuild(buildImage, platform)
+	}
 
-	// Determine output name
-	output := buildOutput
-	if output == "" {
-		// Use current directory name
+	var targets []buildTarget
+	if len(buildTargets) > 0 {
+		var err error
+		if targets, err = parseTargets(buildTargets); err != nil {
+			ui.Error(err.Error())
+			return err
+		}
+	}
+
+	// Use current directory name
+	app := buildOutput
+	if app == "" {
 		cwd, _ := os.Getwd()
-		output = filepath.Base(cwd)
-		if buildOS == "windows" {
-			output += ".exe"
+		app = filepath.Base(cwd)
+	}
+
+	if targets == nil {
+		ui.Info(fmt.Sprintf("Building for %s/%s...", buildOS, buildArch))
+	} else {
+		ui.Info(fmt.Sprintf("Building %s for %d targets...", app, len(targets)))
+	}
+
+	appEnv := os.Getenv("APP_ENV")
+	if appEnv == "" {
+		appEnv = "production"
+	}
+	env, loaded, err := loadEnvironment(appEnv, buildEnvFiles)
+	var missing *missingEnvError
+	if errors.As(err, &missing) {
+		ui.Warning(err.Error())
+	} else if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	reportEnvFiles(loaded)
+
+	project, err := config.LoadProject(".")
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+
+	opts := buildOptions{env: env, stamp: gitStamp(), goVersion: goVersion()}
+	if targets == nil {
+		targets = []buildTarget{{OS: buildOS, Arch: buildArch}}
+	}
+	if opts.cgo, err = cgoSettings(project.Build, env, targets); err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+
+	if buildAssets {
+		if opts.assets, err = buildFrontendAssets(project.Build, env); err != nil {
+			ui.Error(err.Error())
+			return err
 		}
 	}
 
-	ui.Info(fmt.Sprintf("Building for %s/%s...", buildOS, buildArch))
+	if len(buildTargets) > 0 {
+		return runMatrixBuild(opts, app, targets)
+	}
 
-	// Set environment for cross-compilation
-	env := os.Environ()
-	env = append(env, fmt.Sprintf("GOOS=%s", buildOS))
-	env = append(env, fmt.Sprintf("GOARCH=%s", buildArch))
-	env = append(env, "CGO_ENABLED=0")
+	output := app
+	if buildOutput == "" && buildOS == "windows" {
+		output += ".exe"
+	}
 
-	// Build command
-	buildArgs := []string{"build", "-o", output, "-ldflags", "-s -w"}
-	if buildTags != "" {
-		buildArgs = append(buildArgs, "-tags", buildTags)
+	// Read before the build replaces it
+	var previous *buildManifest
+	if buildReport {
+		if previous, err = previousManifest(output); err != nil {
+			ui.Error(err.Error())
+			return err
+		}
+	}
+
+	manifest, err := compileTarget(opts, targets[0], output, os.Stdout)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Built: %s (%s, %s)", output, formatSize(manifest.Size), manifest.Version))
+	ui.Muted("Manifest: " + manifestPath(output))
+
+	if buildReport {
+		if err := reportBuild(opts, targets[0], output, manifest, previous); err != nil {
+			ui.Error(err.Error())
+			return err
+		}
+	}
+	return nil
+}
+
+// previousManifest returns the manifest --report compares with: the one
+// given by --compare, or the last build's when there is one
+func previousManifest(output string) (*buildManifest, error) {
+	if buildCompare != "" {
+		return readManifest(buildCompare)
+	}
+	previous, err := readManifest(manifestPath(output))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return previous, err
+}
+
+// buildOptions is what every target of a build shares
+type buildOptions struct {
+	env       []string
+	stamp     buildStamp
+	goVersion string
+	assets    *assetsManifest
+	cgo       map[buildTarget]cgoSetting
+}
+
+// cgoSettings decides cgo for each target from --cgo, --cc, velocity.yaml
+// and the packages the app depends on, reporting each decision
+func cgoSettings(cfg config.BuildConfig, env []string, targets []buildTarget) (map[buildTarget]cgoSetting, error) {
+	compilers := map[string]string{}
+	for target, cc := range cfg.CC {
+		compilers[target] = cc
+	}
+	flagged, err := parseCompilers(buildCC)
+	if err != nil {
+		return nil, err
+	}
+	for target, cc := range flagged {
+		compilers[target] = cc
+	}
+
+	var modules []string
+	if buildCgo != cgoOn {
+		if modules, err = cgoModules(env); err != nil {
+			modules = knownCgoRequirements()
+		}
+	}
+
+	settings := make(map[buildTarget]cgoSetting, len(targets))
+	for _, target := range targets {
+		setting := resolveCgo(buildCgo, modules, target, compilers)
+		switch {
+		case setting.Warn:
+			ui.Warning(setting.Note)
+		case setting.Note != "":
+			ui.Step(setting.Note)
+		}
+		settings[target] = setting
 	}
-	buildArgs = append(buildArgs, ".")
+	return settings, nil
+}
+
+// compileTarget builds the app for target into output and writes its
+// manifest. Compiler output goes to out.
+func compileTarget(opts buildOptions, target buildTarget, output string, out io.Writer) (*buildManifest, error) {
+	cgo := opts.cgo[target]
+	buildArgs := goBuildArgs(opts, output, opts.stamp.ldflags())
 
 	buildCmd := exec.Command("go", buildArgs...)
-	buildCmd.Env = env
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
+	buildCmd.Env = targetEnv(opts, target)
+	buildCmd.Stdout = out
+	buildCmd.Stderr = out
 
 	if err := buildCmd.Run(); err != nil {
-		ui.Error(fmt.Sprintf("Build failed: %v", err))
-		return err
+		return nil, fmt.Errorf("build failed: %w", err)
 	}
 
-	ui.Success(fmt.Sprintf("Built: %s", output))
-	return nil
+	sum, size, err := fileDigest(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binary: %w", err)
+	}
+	manifest := &buildManifest{
+		Name:      filepath.Base(output),
+		Version:   opts.stamp.Version,
+		Commit:    opts.stamp.Commit,
+		BuildTime: opts.stamp.Time,
+		GoVersion: opts.goVersion,
+		OS:        target.OS,
+		Arch:      target.Arch,
+		SHA256:    sum,
+		Size:      size,
+		CGO:       cgo.Enabled,
+		CC:        cgo.CC,
+		Flags:     buildArgs[1:],
+		Assets:    opts.assets,
+	}
+	if err := writeManifest(output, manifest); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// targetEnv returns the build environment for target
+func targetEnv(opts buildOptions, target buildTarget) []string {
+	// Set environment for cross-compilation
+	env := append(slices.Clip(opts.env), "GOOS="+target.OS, "GOARCH="+target.Arch)
+	return append(env, opts.cgo[target].env()...)
+}
+
+// goBuildArgs returns the go build arguments that link the app into
+// output with ldflags
+func goBuildArgs(opts buildOptions, output, ldflags string) []string {
+	args := []string{"build", "-o", output, "-trimpath", "-ldflags", ldflags}
+	if buildTags != "" {
+		args = append(args, "-tags", buildTags)
+	}
+	if opts.assets != nil {
+		args = append(args, "-overlay", assetsOverlay)
+	}
+	return append(args, ".")
+}
+
+// buildFrontendAssets runs the frontend build and prepares its output to
+// be embedded
+func buildFrontendAssets(cfg config.BuildConfig, env []string) (*assetsManifest, error) {
+	if command := frontendCommand(cfg); command != "" {
+		ui.Step("Building frontend: " + command)
+		if err := runFrontendBuild(command, env); err != nil {
+			return nil, err
+		}
+	} else {
+		ui.Warning("No frontend build script in package.json, embedding existing assets")
+	}
+
+	dir := assetsDir(cfg)
+	files, size, err := assetStats(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no frontend assets to embed in %s: %w", dir, err)
+	}
+	if files == 0 {
+		return nil, fmt.Errorf("no frontend assets to embed in %s", dir)
+	}
+	if err := writeAssetsOverlay(dir); err != nil {
+		return nil, fmt.Errorf("failed to prepare assets: %w", err)
+	}
+
+	ui.Step(fmt.Sprintf("Embedding %d files from %s (%s)", files, dir, formatSize(size)))
+	return &assetsManifest{Dir: dir, Files: files, Size: size}, nil
 }
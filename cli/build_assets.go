@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+// Embedding assets with an overlay leaves the project's own files alone
+const (
+	defaultAssetsDir = "public/build"
+	assetsOverlay    = ".velocity/tmp/assets_overlay.json"
+	assetsSource     = ".velocity/tmp/velocity_assets.go"
+)
+
+// assetsProgram is compiled into the app's main package. The framework
+// serves static files from disk, so embedded assets are written out when
+// the binary runs somewhere they are missing.
+const assetsProgram = `package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+//go:embed all:%[1]s
+var velocityAssets embed.FS
+
+func init() {
+	const dir = %[1]q
+	if _, err := os.Stat(dir); err == nil {
+		return
+	}
+	err := fs.WalkDir(velocityAssets, dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.FromSlash(path)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := velocityAssets.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0644)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "velocity: could not write embedded assets: %%v\n", err)
+	}
+}
+`
+
+// assetsDir returns the directory the frontend build writes to
+func assetsDir(cfg config.BuildConfig) string {
+	if cfg.Assets != "" {
+		return filepath.ToSlash(filepath.Clean(cfg.Assets))
+	}
+	return defaultAssetsDir
+}
+
+// frontendCommand returns the command that builds the frontend, or ""
+// when the project has none
+func frontendCommand(cfg config.BuildConfig) string {
+	if cfg.Frontend != "" {
+		return cfg.Frontend
+	}
+
+	data, err := os.ReadFile("package.json")
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || pkg.Scripts["build"] == "" {
+		return ""
+	}
+
+	if _, err := exec.LookPath("bun"); err == nil {
+		return "bun run build"
+	}
+	return "npm run build"
+}
+
+// runFrontendBuild runs the frontend build command with env
+func runFrontendBuild(command string, env []string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("frontend build failed: %w", err)
+	}
+	return nil
+}
+
+// writeAssetsOverlay writes the file that embeds dir and an overlay
+// adding it to the main package, for go build -overlay
+func writeAssetsOverlay(dir string) error {
+	if err := os.MkdirAll(filepath.Dir(assetsSource), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(assetsSource, fmt.Appendf(nil, assetsProgram, dir), 0644); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	source, err := filepath.Abs(assetsSource)
+	if err != nil {
+		return err
+	}
+	overlay, err := json.Marshal(map[string]map[string]string{
+		"Replace": {filepath.Join(cwd, "zz_velocity_assets.go"): source},
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(assetsOverlay, overlay, 0644)
+}
+
+// assetStats counts the files under dir and their total size
+func assetStats(dir string) (files int, size int64, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		size += info.Size()
+		return nil
+	})
+	return files, size, err
+}
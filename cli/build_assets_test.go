@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+func TestFrontendCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	if got := frontendCommand(config.BuildConfig{}); got != "" {
+		t.Errorf("frontendCommand() without package.json = %q, want none", got)
+	}
+
+	os.WriteFile("package.json", []byte(`{"scripts":{"dev":"vite"}}`), 0644)
+	if got := frontendCommand(config.BuildConfig{}); got != "" {
+		t.Errorf("frontendCommand() without a build script = %q, want none", got)
+	}
+
+	os.WriteFile("package.json", []byte(`{"scripts":{"build":"vite build"}}`), 0644)
+	want := "npm run build"
+	if _, err := exec.LookPath("bun"); err == nil {
+		want = "bun run build"
+	}
+	if got := frontendCommand(config.BuildConfig{}); got != want {
+		t.Errorf("frontendCommand() = %q, want %q", got, want)
+	}
+
+	if got := frontendCommand(config.BuildConfig{Frontend: "make assets"}); got != "make assets" {
+		t.Errorf("frontendCommand() configured = %q, want make assets", got)
+	}
+}
+
+func TestAssetsDir(t *testing.T) {
+	if got := assetsDir(config.BuildConfig{}); got != defaultAssetsDir {
+		t.Errorf("assetsDir() = %q, want %q", got, defaultAssetsDir)
+	}
+	if got := assetsDir(config.BuildConfig{Assets: "./dist/"}); got != "dist" {
+		t.Errorf("assetsDir() = %q, want dist", got)
+	}
+}
+
+func TestLoadProject_BuildAssetsOutsideProject(t *testing.T) {
+	for _, assets := range []string{"../shared", "/var/www", "."} {
+		tmpDir := t.TempDir()
+		os.WriteFile(tmpDir+"/velocity.yaml", []byte("build:\n  assets: "+assets+"\n"), 0644)
+
+		_, err := config.LoadProject(tmpDir)
+		if err == nil || !strings.Contains(err.Error(), "inside the project") {
+			t.Errorf("LoadProject() with assets %q error = %v, want it rejected", assets, err)
+		}
+	}
+}
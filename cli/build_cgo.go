@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// cgo modes for --cgo
+const (
+	cgoAuto = "auto" // On when dependencies need it and a C compiler is available
+	cgoOn   = "on"
+	cgoOff  = "off"
+)
+
+// knownCgoModules need cgo to work. They are looked for in go.mod when
+// the package graph cannot be listed.
+var knownCgoModules = []string{
+	"github.com/mattn/go-sqlite3",
+}
+
+// validateCgoMode checks a --cgo value
+func validateCgoMode(mode string) error {
+	switch mode {
+	case cgoAuto, cgoOn, cgoOff:
+		return nil
+	}
+	return fmt.Errorf("invalid cgo mode: %q (expected auto, on or off)", mode)
+}
+
+// cgoModules returns the non-standard modules with packages the app
+// compiles with cgo, sorted
+func cgoModules(env []string) ([]string, error) {
+	args := []string{"list", "-deps", "-f", "{{if and .CgoFiles (not .Standard)}}{{with .Module}}{{.Path}}{{end}}{{end}}"}
+	if buildTags != "" {
+		args = append(args, "-tags", buildTags)
+	}
+	args = append(args, ".")
+
+	cmd := exec.Command("go", args...)
+	cmd.Env = append(slices.Clip(env), "CGO_ENABLED=1")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+
+	var modules []string
+	for _, path := range strings.Fields(string(out)) {
+		if !slices.Contains(modules, path) {
+			modules = append(modules, path)
+		}
+	}
+	slices.Sort(modules)
+	return modules, nil
+}
+
+// knownCgoRequirements returns the modules in go.mod known to need cgo
+func knownCgoRequirements() []string {
+	data, err := os.ReadFile("go.mod")
+	if err != nil {
+		return nil
+	}
+	file, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil
+	}
+
+	var modules []string
+	for _, r := range file.Require {
+		if slices.Contains(knownCgoModules, r.Mod.Path) {
+			modules = append(modules, r.Mod.Path)
+		}
+	}
+	return modules
+}
+
+// cgoSetting is how cgo is configured for one target
+type cgoSetting struct {
+	Enabled bool
+	CC      string
+
+	// Note explains the choice when dependencies need cgo; Warn marks
+	// notes about a binary that will not work as expected
+	Note string
+	Warn bool
+}
+
+// resolveCgo decides whether target is built with cgo. compilers maps
+// os/arch targets to C compilers. In auto mode cgo is used when modules
+// need it and a C compiler for the target is available: the host's own
+// for native builds, or a configured one for cross builds.
+func resolveCgo(mode string, modules []string, target buildTarget, compilers map[string]string) cgoSetting {
+	cc := compilers[target.String()]
+	native := target.OS == runtime.GOOS && target.Arch == runtime.GOARCH
+	needed := strings.Join(modules, ", ")
+
+	switch mode {
+	case cgoOff:
+		if len(modules) == 0 {
+			return cgoSetting{}
+		}
+		return cgoSetting{
+			Note: fmt.Sprintf("%s: cgo is off but %s needs it; the binary will fail where it is used", target, needed),
+			Warn: true,
+		}
+
+	case cgoOn:
+		setting := cgoSetting{Enabled: true, CC: cc}
+		if cc == "" && !native {
+			setting.Note = fmt.Sprintf("%s: no C cross-compiler configured, set one with --cc %s=<compiler> or build.cc in velocity.yaml", target, target)
+			setting.Warn = true
+		}
+		return setting
+	}
+
+	if len(modules) == 0 {
+		return cgoSetting{}
+	}
+	if cc != "" {
+		return cgoSetting{Enabled: true, CC: cc, Note: fmt.Sprintf("%s: building with cgo for %s", target, needed)}
+	}
+	if native {
+		if hostCompiler() == "" {
+			return cgoSetting{
+				Note: fmt.Sprintf("%s: %s needs cgo but no C compiler was found, so the binary will fail where it is used; install gcc or clang, or set CC", target, needed),
+				Warn: true,
+			}
+		}
+		return cgoSetting{Enabled: true, Note: fmt.Sprintf("%s: building with cgo for %s", target, needed)}
+	}
+	return cgoSetting{
+		Note: fmt.Sprintf("%s: %s needs cgo but no C cross-compiler is configured, so the binary will fail where it is used; set one with --cc %s=<compiler> or build.cc in velocity.yaml", target, needed, target),
+		Warn: true,
+	}
+}
+
+// hostCompiler returns the C compiler go build would use for a native
+// build: the one named by CC, or else cc or gcc. It is empty when none
+// is on PATH.
+func hostCompiler() string {
+	if cc := strings.Fields(os.Getenv("CC")); len(cc) > 0 {
+		if _, err := exec.LookPath(cc[0]); err != nil {
+			return ""
+		}
+		return cc[0]
+	}
+	for _, name := range []string{"cc", "gcc"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseCompilers reads --cc values of the form os/arch=compiler
+func parseCompilers(values []string) (map[string]string, error) {
+	compilers := map[string]string{}
+	for _, value := range values {
+		target, cc, ok := strings.Cut(value, "=")
+		if !ok || !strings.Contains(target, "/") || cc == "" {
+			return nil, fmt.Errorf("invalid --cc %q (expected os/arch=compiler)", value)
+		}
+		compilers[target] = cc
+	}
+	return compilers, nil
+}
+
+// env returns the environment entries that apply the setting
+func (s cgoSetting) env() []string {
+	if !s.Enabled {
+		return []string{"CGO_ENABLED=0"}
+	}
+	env := []string{"CGO_ENABLED=1"}
+	if s.CC != "" {
+		env = append(env, "CC="+s.CC)
+	}
+	return env
+}
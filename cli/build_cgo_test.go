@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+// fakeCompiler puts an empty cc script on PATH so native cgo builds find
+// a C compiler
+func fakeCompiler(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "cc"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CC", "")
+	t.Setenv("PATH", bin)
+}
+
+func TestResolveCgo(t *testing.T) {
+	fakeCompiler(t)
+	native := buildTarget{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	cross := buildTarget{OS: "linux", Arch: "riscv64"}
+	if native == cross {
+		cross.Arch = "mips64"
+	}
+	sqlite := []string{"github.com/mattn/go-sqlite3"}
+	compilers := map[string]string{"windows/amd64": "x86_64-w64-mingw32-gcc"}
+	windows := buildTarget{OS: "windows", Arch: "amd64"}
+
+	tests := []struct {
+		name    string
+		mode    string
+		modules []string
+		target  buildTarget
+		enabled bool
+		cc      string
+		warn    bool
+	}{
+		{"auto without cgo dependencies", cgoAuto, nil, cross, false, "", false},
+		{"auto native", cgoAuto, sqlite, native, true, "", false},
+		{"auto cross with a compiler", cgoAuto, sqlite, windows, true, "x86_64-w64-mingw32-gcc", false},
+		{"auto cross without a compiler", cgoAuto, sqlite, cross, false, "", true},
+		{"off without cgo dependencies", cgoOff, nil, native, false, "", false},
+		{"off with cgo dependencies", cgoOff, sqlite, native, false, "", true},
+		{"on native", cgoOn, nil, native, true, "", false},
+		{"on cross without a compiler", cgoOn, nil, cross, true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.target == windows && native == windows {
+				t.Skip("windows/amd64 is the host")
+			}
+			got := resolveCgo(tt.mode, tt.modules, tt.target, compilers)
+			if got.Enabled != tt.enabled || got.CC != tt.cc || got.Warn != tt.warn {
+				t.Errorf("resolveCgo() = %+v, want enabled=%v cc=%q warn=%v", got, tt.enabled, tt.cc, tt.warn)
+			}
+		})
+	}
+}
+
+func TestResolveCgo_NativeWithoutCompiler(t *testing.T) {
+	native := buildTarget{OS: runtime.GOOS, Arch: runtime.GOARCH}
+	sqlite := []string{"github.com/mattn/go-sqlite3"}
+
+	t.Setenv("CC", "")
+	t.Setenv("PATH", t.TempDir())
+	got := resolveCgo(cgoAuto, sqlite, native, nil)
+	if got.Enabled || !got.Warn {
+		t.Errorf("resolveCgo() = %+v, want cgo off with a warning", got)
+	}
+
+	// CC naming a compiler that is not installed is no better
+	fakeCompiler(t)
+	t.Setenv("CC", "clang -fno-pie")
+	if got := resolveCgo(cgoAuto, sqlite, native, nil); got.Enabled || !got.Warn {
+		t.Errorf("resolveCgo() with a missing CC = %+v, want cgo off with a warning", got)
+	}
+}
+
+func TestCgoSetting_Env(t *testing.T) {
+	if got := (cgoSetting{}).env(); !reflect.DeepEqual(got, []string{"CGO_ENABLED=0"}) {
+		t.Errorf("env() = %v", got)
+	}
+	got := cgoSetting{Enabled: true, CC: "zig cc"}.env()
+	if !reflect.DeepEqual(got, []string{"CGO_ENABLED=1", "CC=zig cc"}) {
+		t.Errorf("env() = %v", got)
+	}
+}
+
+func TestParseCompilers(t *testing.T) {
+	got, err := parseCompilers([]string{"linux/arm64=aarch64-linux-gnu-gcc", "windows/amd64=zig cc -target x86_64-windows"})
+	if err != nil {
+		t.Fatalf("parseCompilers() error = %v", err)
+	}
+	want := map[string]string{"linux/arm64": "aarch64-linux-gnu-gcc", "windows/amd64": "zig cc -target x86_64-windows"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCompilers() = %v, want %v", got, want)
+	}
+
+	for _, value := range []string{"gcc", "linux=gcc", "linux/arm64="} {
+		if _, err := parseCompilers([]string{value}); err == nil {
+			t.Errorf("parseCompilers(%q) should error", value)
+		}
+	}
+}
+
+func TestValidateCgoMode(t *testing.T) {
+	for _, mode := range []string{cgoAuto, cgoOn, cgoOff} {
+		if err := validateCgoMode(mode); err != nil {
+			t.Errorf("validateCgoMode(%q) error = %v", mode, err)
+		}
+	}
+	if err := validateCgoMode("yes"); err == nil {
+		t.Error("validateCgoMode(yes) should error")
+	}
+}
+
+func TestCgoModules(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module testbuild\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nimport _ \"testbuild/db\"\n\nfunc main() {}\n"), 0644)
+	os.Mkdir("db", 0755)
+	os.WriteFile("db/db.go", []byte("package db\n\nimport \"C\"\n"), 0644)
+
+	modules, err := cgoModules(os.Environ())
+	if err != nil {
+		t.Fatalf("cgoModules() error = %v", err)
+	}
+	if !reflect.DeepEqual(modules, []string{"testbuild"}) {
+		t.Errorf("cgoModules() = %v, want [testbuild]", modules)
+	}
+}
+
+func TestKnownCgoRequirements(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte(`module testbuild
+
+go 1.21
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.22
+	github.com/spf13/cobra v1.8.0
+)
+`), 0644)
+
+	if got := knownCgoRequirements(); !reflect.DeepEqual(got, []string{"github.com/mattn/go-sqlite3"}) {
+		t.Errorf("knownCgoRequirements() = %v", got)
+	}
+}
+
+func TestRunBuild_SwitchesOnCgoForNativeBuilds(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("no C compiler")
+	}
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module testbuild\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte(`package main
+
+// static int answer(void) { return 42; }
+import "C"
+
+import "fmt"
+
+func main() { fmt.Println(C.answer()) }
+`), 0644)
+
+	buildOutput = "withcgo"
+	buildOS = runtime.GOOS
+	buildArch = runtime.GOARCH
+	buildTags = ""
+	buildCgo = cgoAuto
+	if err := runBuild(nil, nil); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	output, err := exec.Command("./withcgo").Output()
+	if err != nil || string(output) != "42\n" {
+		t.Errorf("binary output = %q, %v", output, err)
+	}
+
+	data, _ := os.ReadFile("withcgo.manifest.json")
+	var manifest buildManifest
+	json.Unmarshal(data, &manifest)
+	if !manifest.CGO {
+		t.Error("manifest should record a cgo build")
+	}
+}
+
+func TestLoadProject_BuildCC(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(tmpDir+"/velocity.yaml", []byte("build:\n  cc:\n    linux/arm64: aarch64-linux-gnu-gcc\n"), 0644)
+	project, err := config.LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if project.Build.CC["linux/arm64"] != "aarch64-linux-gnu-gcc" {
+		t.Errorf("build cc = %v", project.Build.CC)
+	}
+
+	os.WriteFile(tmpDir+"/velocity.yaml", []byte("build:\n  cc:\n    arm64: gcc\n"), 0644)
+	if _, err := config.LoadProject(tmpDir); err == nil {
+		t.Error("LoadProject() should reject a cc target without os/arch")
+	}
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// runImageBuild builds the project's Docker image as image, generating a
+// Dockerfile and .dockerignore first when the project has none. An empty
+// platform builds for the docker host.
+func runImageBuild(image, platform string) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		err := fmt.Errorf("docker is not installed or not on PATH")
+		ui.Error(err.Error())
+		return err
+	}
+
+	if err := ensureDockerFiles(); err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+
+	stamp := gitStamp()
+	args := dockerBuildArgs(image, platform, stamp)
+	ui.Info(fmt.Sprintf("Building image %s...", image))
+	ui.Muted(ui.Command("docker " + strings.Join(args, " ")))
+
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		err = fmt.Errorf("docker build failed: %w", err)
+		ui.Error(err.Error())
+		return err
+	}
+
+	size, err := imageSize(image)
+	if err != nil {
+		ui.Warning(fmt.Sprintf("Built %s but could not read its size: %v", image, err))
+		return nil
+	}
+	ui.Success(fmt.Sprintf("Built image: %s (%s, %s)", image, formatSize(size), stamp.Version))
+	return nil
+}
+
+// ensureDockerFiles writes whichever of the Dockerfile and .dockerignore
+// the project is missing
+func ensureDockerFiles() error {
+	var missing []int
+	for i, file := range dockerFiles {
+		if _, err := os.Stat(file.Path); os.IsNotExist(err) {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	data, err := projectDockerfileData()
+	if err != nil {
+		return err
+	}
+	reportDockerfileData(data)
+
+	created := &generatedFiles{}
+	for _, i := range missing {
+		file := dockerFiles[i]
+		if _, err := writeStub(created, file.Stub, file.Path, file.Path, data, false); err != nil {
+			created.rollback()
+			return err
+		}
+		ui.Step("Generated " + file.Path)
+	}
+	return nil
+}
+
+// dockerBuildArgs returns the docker build arguments for image. The
+// version, commit and build time are passed on to be stamped into the
+// binary, as build does.
+func dockerBuildArgs(image, platform string, stamp buildStamp) []string {
+	args := []string{"build", "-t", image,
+		"--build-arg", "VERSION=" + stamp.Version,
+		"--build-arg", "COMMIT=" + stamp.Commit,
+		"--build-arg", "BUILD_TIME=" + stamp.Time,
+	}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	return append(args, ".")
+}
+
+// imageSize returns the size of a local image in bytes
+func imageSize(image string) (int64, error) {
+	out, err := exec.Command("docker", "image", "inspect", "--format", "{{.Size}}", image).Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+}
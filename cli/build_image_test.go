@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeDocker puts a docker script on PATH that logs its arguments to the
+// returned file and reports an image size of 52428800 bytes
+func fakeDocker(t *testing.T) string {
+	t.Helper()
+	bin := t.TempDir()
+	log := filepath.Join(bin, "docker.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\nif [ \"$1\" = image ]; then echo 52428800; fi\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestDockerBuildArgs(t *testing.T) {
+	stamp := buildStamp{Version: "v1.2.0", Commit: "abc123", Time: "2026-01-02T03:04:05Z"}
+
+	got := dockerBuildArgs("shop:1.2.0", "", stamp)
+	want := []string{"build", "-t", "shop:1.2.0", "--build-arg", "VERSION=v1.2.0", "--build-arg", "COMMIT=abc123", "--build-arg", "BUILD_TIME=2026-01-02T03:04:05Z", "."}
+	if !slices.Equal(got, want) {
+		t.Errorf("dockerBuildArgs() = %v, want %v", got, want)
+	}
+
+	got = dockerBuildArgs("shop:1.2.0", "linux/arm64", stamp)
+	if !strings.Contains(strings.Join(got, " "), "--platform linux/arm64 .") {
+		t.Errorf("dockerBuildArgs() = %v, want --platform before the context", got)
+	}
+}
+
+func TestRunBuild_Image(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	log := fakeDocker(t)
+	os.WriteFile("go.mod", []byte("module shop\n\ngo 1.25\n"), 0644)
+	os.WriteFile(dockerignorePath, []byte("custom\n"), 0644)
+
+	buildImage = "shop:dev"
+	defer func() { buildImage = "" }()
+
+	if err := runBuild(nil, nil); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	if _, err := os.Stat(dockerfilePath); err != nil {
+		t.Error("a missing Dockerfile should be generated")
+	}
+	if content, _ := os.ReadFile(dockerignorePath); string(content) != "custom\n" {
+		t.Error("an existing .dockerignore should be kept")
+	}
+
+	calls, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(calls)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("docker calls = %q, want build and inspect", lines)
+	}
+	if !strings.HasPrefix(lines[0], "build -t shop:dev --build-arg VERSION=") || !strings.HasSuffix(lines[0], " .") {
+		t.Errorf("docker build call = %q", lines[0])
+	}
+	if lines[1] != "image inspect --format {{.Size}} shop:dev" {
+		t.Errorf("docker inspect call = %q", lines[1])
+	}
+}
+
+func TestRunBuild_ImageWithoutDocker(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	buildImage = "shop:dev"
+	defer func() { buildImage = "" }()
+
+	err := runBuild(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "docker is not installed") {
+		t.Errorf("runBuild() error = %v, want docker not found", err)
+	}
+}
+
+func TestImageSize(t *testing.T) {
+	fakeDocker(t)
+
+	size, err := imageSize("shop:dev")
+	if err != nil {
+		t.Fatalf("imageSize() error = %v", err)
+	}
+	if size != 52428800 {
+		t.Errorf("imageSize() = %d, want 52428800", size)
+	}
+}
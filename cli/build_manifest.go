@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Package main variables stamped with the build's version details, as
+// in -X main.version=v1.2.0. Apps that do not declare them are unaffected.
+const (
+	versionVar   = "main.version"
+	commitVar    = "main.commit"
+	buildTimeVar = "main.date"
+)
+
+// buildStamp is the version details a binary is stamped with
+type buildStamp struct {
+	Version string
+	Commit  string
+	Time    string
+}
+
+// gitStamp reads the version and commit from git. Outside a repository
+// the version is "dev". SOURCE_DATE_EPOCH overrides the build time so
+// builds can be reproduced.
+func gitStamp() buildStamp {
+	stamp := buildStamp{Version: "dev", Time: time.Now().UTC().Format(time.RFC3339)}
+
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if secs, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			stamp.Time = time.Unix(secs, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	if out, err := exec.Command("git", "describe", "--tags", "--always", "--dirty").Output(); err == nil {
+		stamp.Version = strings.TrimSpace(string(out))
+	}
+	if out, err := exec.Command("git", "rev-parse", "HEAD").Output(); err == nil {
+		stamp.Commit = strings.TrimSpace(string(out))
+	}
+	return stamp
+}
+
+// ldflags returns the linker flags for a stripped, stamped binary
+func (s buildStamp) ldflags() string {
+	return "-s -w " + s.stampFlags()
+}
+
+// stampFlags returns the linker flags that set the version variables
+func (s buildStamp) stampFlags() string {
+	flags := []string{
+		"-X", versionVar + "=" + s.Version,
+		"-X", buildTimeVar + "=" + s.Time,
+	}
+	if s.Commit != "" {
+		flags = append(flags, "-X", commitVar+"="+s.Commit)
+	}
+	return strings.Join(flags, " ")
+}
+
+// buildManifest describes a built binary. It is written next to the
+// binary as <binary>.manifest.json.
+type buildManifest struct {
+	Name      string          `json:"name"`
+	Version   string          `json:"version"`
+	Commit    string          `json:"commit,omitempty"`
+	BuildTime string          `json:"build_time"`
+	GoVersion string          `json:"go_version"`
+	OS        string          `json:"os"`
+	Arch      string          `json:"arch"`
+	SHA256    string          `json:"sha256"`
+	Size      int64           `json:"size"`
+	CGO       bool            `json:"cgo"`
+	CC        string          `json:"cc,omitempty"`
+	Flags     []string        `json:"flags"`
+	Assets    *assetsManifest `json:"assets,omitempty"`
+
+	// Packages maps packages to the bytes they add, written by --report
+	Packages map[string]int64 `json:"packages,omitempty"`
+}
+
+// assetsManifest describes the frontend assets embedded in a binary
+type assetsManifest struct {
+	Dir   string `json:"dir"`
+	Files int    `json:"files"`
+	Size  int64  `json:"size"`
+}
+
+// manifestPath returns where the manifest for a binary is written
+func manifestPath(binary string) string {
+	return binary + ".manifest.json"
+}
+
+// goVersion returns the version of the go command used to build
+func goVersion() string {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// fileDigest returns the sha256 and size of a file
+func fileDigest(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// writeManifest writes m next to binary
+func writeManifest(binary string, m *buildManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(binary), append(data, '\n'), 0644)
+}
+
+// readManifest reads a manifest written by writeManifest
+func readManifest(path string) (*buildManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m buildManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// formatSize renders a byte count for people
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
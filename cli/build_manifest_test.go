@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildStamp_Ldflags(t *testing.T) {
+	stamp := buildStamp{Version: "v1.0.0", Commit: "abc123", Time: "2024-01-01T00:00:00Z"}
+	want := "-s -w -X main.version=v1.0.0 -X main.date=2024-01-01T00:00:00Z -X main.commit=abc123"
+	if got := stamp.ldflags(); got != want {
+		t.Errorf("ldflags() = %q, want %q", got, want)
+	}
+
+	// Outside git there is no commit to stamp
+	stamp.Commit = ""
+	want = "-s -w -X main.version=v1.0.0 -X main.date=2024-01-01T00:00:00Z"
+	if got := stamp.ldflags(); got != want {
+		t.Errorf("ldflags() = %q, want %q", got, want)
+	}
+}
+
+func TestGitStamp_OutsideRepository(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	t.Setenv("SOURCE_DATE_EPOCH", "0")
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(tmpDir))
+
+	stamp := gitStamp()
+	if stamp.Version != "dev" || stamp.Commit != "" {
+		t.Errorf("gitStamp() = %+v, want version dev and no commit", stamp)
+	}
+	if stamp.Time != "1970-01-01T00:00:00Z" {
+		t.Errorf("gitStamp() time = %q, want SOURCE_DATE_EPOCH", stamp.Time)
+	}
+}
+
+func TestFileDigest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binary")
+	os.WriteFile(path, []byte("velocity"), 0644)
+
+	sum, size, err := fileDigest(path)
+	if err != nil {
+		t.Fatalf("fileDigest() error = %v", err)
+	}
+	want := sha256.Sum256([]byte("velocity"))
+	if sum != hex.EncodeToString(want[:]) || size != 8 {
+		t.Errorf("fileDigest() = %s, %d", sum, size)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:             "512 B",
+		2048:            "2.0 KB",
+		5 * 1024 * 1024: "5.0 MB",
+	}
+	for size, want := range tests {
+		if got := formatSize(size); got != want {
+			t.Errorf("formatSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package cli
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+const (
+	reportBinary     = ".velocity/tmp/report_binary"
+	reportTop        = 15   // Packages listed in the breakdown
+	reportIncreases  = 5    // Package increases listed in a comparison
+	regressionGrowth = 0.05 // Growth over which a build is flagged
+
+	stdModule     = "std"
+	metadataGroup = "(metadata)" // Type and function tables of the runtime
+	otherGroup    = "(other)"    // Symbols outside any Go package, e.g. from C
+)
+
+// packageSize is what a package or module adds to a binary
+type packageSize struct {
+	Path    string
+	Version string // Set for modules
+	Size    int64
+}
+
+// sizeReport breaks a binary's symbols down by package and module
+type sizeReport struct {
+	Packages []packageSize // Largest first
+	Modules  []packageSize // Largest first
+	Total    int64
+}
+
+// reportBuild analyses the binary built for target and prints where its
+// size goes, compared with previous when there is one. Package sizes are
+// recorded in the manifest so the next report can compare them.
+func reportBuild(opts buildOptions, target buildTarget, output string, manifest, previous *buildManifest) error {
+	ui.Newline()
+	ui.Step("Linking with symbols for the size report...")
+
+	// The build's own binary is stripped, so sizes come from a copy that
+	// keeps its symbol table
+	if err := linkSymbols(opts, target); err != nil {
+		return err
+	}
+	defer os.Remove(reportBinary)
+
+	packages, err := symbolSizes(reportBinary)
+	if err != nil {
+		return err
+	}
+	info, err := buildinfo.ReadFile(output)
+	if err != nil {
+		return fmt.Errorf("failed to read build info: %w", err)
+	}
+
+	manifest.Packages = packages
+	if err := writeManifest(output, manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	printSizeReport(newSizeReport(packages, info), manifest)
+	if previous != nil {
+		ui.Newline()
+		printSizeComparison(manifest, previous)
+	}
+	return nil
+}
+
+// linkSymbols builds target into reportBinary without stripping it
+func linkSymbols(opts buildOptions, target buildTarget) error {
+	if err := os.MkdirAll(filepath.Dir(reportBinary), 0755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", goBuildArgs(opts, reportBinary, opts.stamp.stampFlags())...)
+	cmd.Env = targetEnv(opts, target)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		fmt.Print(string(out))
+		return fmt.Errorf("failed to link the report binary: %w", err)
+	}
+	return nil
+}
+
+// symbolSizes sums the symbol sizes in binary by package
+func symbolSizes(binary string) (map[string]int64, error) {
+	out, err := exec.Command("go", "tool", "nm", "-size", binary).Output()
+	if err != nil {
+		return nil, fmt.Errorf("go tool nm: %w", err)
+	}
+	return parseSymbolSizes(string(out)), nil
+}
+
+// parseSymbolSizes reads go tool nm -size output. Only symbols stored in
+// the file count: text, data and read-only data, not zeroed (bss) data.
+func parseSymbolSizes(out string) map[string]int64 {
+	sizes := map[string]int64{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || len(fields[2]) != 1 || !strings.Contains("TtDdRr", fields[2]) {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil || size == 0 {
+			continue
+		}
+		sizes[symbolPackage(strings.Join(fields[3:], " "))] += size
+	}
+	return sizes
+}
+
+// symbolPackage returns the import path of the package a symbol belongs to
+func symbolPackage(name string) string {
+	if strings.HasPrefix(name, "go:") || strings.HasPrefix(name, "type:") {
+		return metadataGroup
+	}
+
+	// Type arguments of generic functions name other packages
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		name = name[:i]
+	}
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return otherGroup
+	}
+	return unescapeSymbolPath(name[:slash+1+dot])
+}
+
+// unescapeSymbolPath undoes the escaping the linker applies to the last
+// element of a package path in symbol names, where gopkg.in/yaml.v3
+// becomes gopkg.in/yaml%2ev3 and the % itself may be escaped again
+func unescapeSymbolPath(path string) string {
+	for strings.Contains(path, "%") {
+		unescaped, err := url.PathUnescape(path)
+		if err != nil || unescaped == path {
+			break
+		}
+		path = unescaped
+	}
+	return path
+}
+
+// newSizeReport ranks packages and groups them by the modules in info
+func newSizeReport(packages map[string]int64, info *debug.BuildInfo) *sizeReport {
+	report := &sizeReport{}
+	modules := map[string]*packageSize{}
+	for pkg, size := range packages {
+		report.Total += size
+		report.Packages = append(report.Packages, packageSize{Path: pkg, Size: size})
+
+		path, version := packageModule(pkg, info)
+		if modules[path] == nil {
+			modules[path] = &packageSize{Path: path, Version: version}
+		}
+		modules[path].Size += size
+	}
+	for _, m := range modules {
+		report.Modules = append(report.Modules, *m)
+	}
+
+	rankSizes(report.Packages)
+	rankSizes(report.Modules)
+	return report
+}
+
+// packageModule returns the path and version of the module providing pkg.
+// Standard library packages belong to std, at the Go version.
+func packageModule(pkg string, info *debug.BuildInfo) (string, string) {
+	if pkg == metadataGroup || pkg == otherGroup {
+		return pkg, ""
+	}
+	if pkg == "main" {
+		return info.Main.Path, info.Main.Version
+	}
+
+	var best *debug.Module
+	for _, m := range append([]*debug.Module{&info.Main}, info.Deps...) {
+		if within(pkg, m.Path) && (best == nil || len(m.Path) > len(best.Path)) {
+			best = m
+		}
+	}
+	if best == nil {
+		return stdModule, info.GoVersion
+	}
+
+	version := best.Version
+	if best.Replace != nil {
+		version = best.Replace.Path
+		if best.Replace.Version != "" {
+			version += "@" + best.Replace.Version
+		}
+	}
+	return best.Path, version
+}
+
+// within reports whether pkg is inside the module at path
+func within(pkg, path string) bool {
+	return path != "" && (pkg == path || strings.HasPrefix(pkg, path+"/"))
+}
+
+// rankSizes sorts sizes largest first, then by path
+func rankSizes(sizes []packageSize) {
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].Size != sizes[j].Size {
+			return sizes[i].Size > sizes[j].Size
+		}
+		return sizes[i].Path < sizes[j].Path
+	})
+}
+
+// printSizeReport prints the ranked breakdown of the binary in manifest
+func printSizeReport(report *sizeReport, manifest *buildManifest) {
+	ui.Info("Size by package")
+	var rows [][]string
+	for i, p := range report.Packages {
+		if i == reportTop {
+			break
+		}
+		rows = append(rows, []string{strconv.Itoa(i + 1), p.Path, formatSize(p.Size), share(p.Size, report.Total)})
+	}
+	ui.Table([]string{"#", "PACKAGE", "SIZE", "SHARE"}, rows)
+	if rest := len(report.Packages) - reportTop; rest > 0 {
+		var size int64
+		for _, p := range report.Packages[reportTop:] {
+			size += p.Size
+		}
+		ui.Muted(fmt.Sprintf("  ...and %d more packages (%s)", rest, formatSize(size)))
+	}
+
+	ui.Newline()
+	ui.Info("Size by module")
+	rows = nil
+	for _, m := range report.Modules {
+		rows = append(rows, []string{m.Path, m.Version, formatSize(m.Size), share(m.Size, report.Total)})
+	}
+	ui.Table([]string{"MODULE", "VERSION", "SIZE", "SHARE"}, rows)
+
+	ui.Newline()
+	if a := manifest.Assets; a != nil {
+		ui.Step(fmt.Sprintf("Embedded assets: %d files from %s (%s, %s of the binary)", a.Files, a.Dir, formatSize(a.Size), share(a.Size, manifest.Size)))
+	} else {
+		ui.Muted("No embedded assets")
+	}
+	ui.Muted(fmt.Sprintf("Symbols total %s of the %s binary", formatSize(report.Total), formatSize(manifest.Size)))
+}
+
+// printSizeComparison reports how the binary changed since previous and
+// flags growth beyond regressionGrowth
+func printSizeComparison(current, previous *buildManifest) {
+	since := previous.Version
+	if since == "" {
+		since = "the previous build"
+	}
+
+	delta := current.Size - previous.Size
+	message := fmt.Sprintf("Binary %s (%s) since %s: %s -> %s",
+		formatDelta(delta), growth(delta, previous.Size), since, formatSize(previous.Size), formatSize(current.Size))
+	if regressed(delta, previous.Size) {
+		ui.Warning("Size regression: " + message)
+	} else {
+		ui.Step(message)
+	}
+
+	if current.Assets != nil || previous.Assets != nil {
+		var before, after int64
+		if previous.Assets != nil {
+			before = previous.Assets.Size
+		}
+		if current.Assets != nil {
+			after = current.Assets.Size
+		}
+		if after != before {
+			ui.Step(fmt.Sprintf("Embedded assets %s: %s -> %s", formatDelta(after-before), formatSize(before), formatSize(after)))
+		}
+	}
+
+	if previous.Packages == nil {
+		ui.Muted("The previous manifest has no package sizes to compare; it was built without --report")
+		return
+	}
+
+	increases := sizeIncreases(current.Packages, previous.Packages)
+	if len(increases) == 0 {
+		ui.Step("No package grew")
+		return
+	}
+	var rows [][]string
+	for i, p := range increases {
+		if i == reportIncreases {
+			break
+		}
+		before := previous.Packages[p.Path]
+		change := formatDelta(p.Size)
+		if before == 0 {
+			change += " (new)"
+		} else if regressed(p.Size, before) {
+			change = ui.ErrorText(change + " (" + growth(p.Size, before) + ")")
+		}
+		rows = append(rows, []string{p.Path, formatSize(before), formatSize(current.Packages[p.Path]), change})
+	}
+	ui.Info("Largest increases")
+	ui.Table([]string{"PACKAGE", "BEFORE", "AFTER", "CHANGE"}, rows)
+}
+
+// sizeIncreases returns the packages that grew from previous to current
+// with their growth, largest first
+func sizeIncreases(current, previous map[string]int64) []packageSize {
+	var increases []packageSize
+	for pkg, size := range current {
+		if grown := size - previous[pkg]; grown > 0 {
+			increases = append(increases, packageSize{Path: pkg, Size: grown})
+		}
+	}
+	rankSizes(increases)
+	return increases
+}
+
+// regressed reports whether growing by delta from before is a regression
+func regressed(delta, before int64) bool {
+	return before > 0 && float64(delta)/float64(before) > regressionGrowth
+}
+
+// share renders size as a percentage of total
+func share(size, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(size)*100/float64(total))
+}
+
+// growth renders delta as a signed percentage of before
+func growth(delta, before int64) string {
+	if before == 0 {
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", float64(delta)*100/float64(before))
+}
+
+// formatDelta renders a signed byte count
+func formatDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + formatSize(-delta)
+	}
+	return "+" + formatSize(delta)
+}
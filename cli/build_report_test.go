@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"testing"
+)
+
+func TestSymbolPackage(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"main.main", "main"},
+		{"runtime.mallocgc", "runtime"},
+		{"fmt.(*pp).printValue", "fmt"},
+		{"github.com/velocitykode/velocity/pkg/orm.(*DB).Query", "github.com/velocitykode/velocity/pkg/orm"},
+		{"vendor/golang.org/x/net/idna.idnaValues", "vendor/golang.org/x/net/idna"},
+		{"slices.Sort[go.shape.[]github.com/shop/app.Item]", "slices"},
+		{"crypto/sha1.blockAVX2.abi0", "crypto/sha1"},
+		{"go:func.*", metadataGroup},
+		{"type:.eq.EIM1", metadataGroup},
+		{"x_cgo_init", otherGroup},
+		{"gopkg.in/yaml%2ev3.(*Node).Decode", "gopkg.in/yaml.v3"},
+		{"gopkg.in/yaml%252ev3.init", "gopkg.in/yaml.v3"},
+		{"github.com/shop/app/internal/v1%2e2.Handler", "github.com/shop/app/internal/v1.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := symbolPackage(tt.name); got != tt.want {
+				t.Errorf("symbolPackage(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSymbolSizes(t *testing.T) {
+	out := `  4a0a80       9349 T time.Time.appendFormat
+  4a2000        651 t time.now
+  7457a0      19650 D vendor/golang.org/x/text/unicode/norm.decomps
+  69f418     228448 r go:func.*
+  776740      93464 B runtime.mheap_
+  772880        100 b runtime.semtable
+  6d99d8          0 R type:*
+                    U malloc
+  401000        200 T main.main
+`
+	got := parseSymbolSizes(out)
+	want := map[string]int64{
+		"time":                                  10000,
+		"vendor/golang.org/x/text/unicode/norm": 19650,
+		metadataGroup:                           228448,
+		"main":                                  200,
+	}
+
+	if len(got) != len(want) {
+		t.Errorf("parseSymbolSizes() = %v, want %v", got, want)
+	}
+	for pkg, size := range want {
+		if got[pkg] != size {
+			t.Errorf("size of %s = %d, want %d", pkg, got[pkg], size)
+		}
+	}
+}
+
+func TestNewSizeReport(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.25.1",
+		Main:      debug.Module{Path: "github.com/shop/app", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/velocitykode/velocity", Version: "v0.0.3"},
+			{Path: "github.com/velocitykode/velocity/pkg/extra", Version: "v1.0.0"},
+			{Path: "github.com/lib/pq", Version: "v1.10.9", Replace: &debug.Module{Path: "../pq"}},
+		},
+	}
+	packages := map[string]int64{
+		"main":                       100,
+		"github.com/shop/app/routes": 50,
+		"github.com/velocitykode/velocity/pkg/orm":         300,
+		"github.com/velocitykode/velocity/pkg/extra/cache": 40,
+		"github.com/lib/pq":                                200,
+		"runtime":                                          500,
+		"fmt":                                              80,
+		metadataGroup:                                      120,
+	}
+
+	report := newSizeReport(packages, info)
+
+	if report.Total != 1390 {
+		t.Errorf("Total = %d, want 1390", report.Total)
+	}
+	if report.Packages[0].Path != "runtime" || report.Packages[1].Path != "github.com/velocitykode/velocity/pkg/orm" {
+		t.Errorf("Packages should be ranked largest first, got %v", report.Packages[:2])
+	}
+
+	want := []packageSize{
+		{stdModule, "go1.25.1", 580},
+		{"github.com/velocitykode/velocity", "v0.0.3", 300},
+		{"github.com/lib/pq", "../pq", 200},
+		{"github.com/shop/app", "(devel)", 150},
+		{metadataGroup, "", 120},
+		{"github.com/velocitykode/velocity/pkg/extra", "v1.0.0", 40},
+	}
+	if len(report.Modules) != len(want) {
+		t.Fatalf("Modules = %v, want %v", report.Modules, want)
+	}
+	for i := range want {
+		if report.Modules[i] != want[i] {
+			t.Errorf("Modules[%d] = %v, want %v", i, report.Modules[i], want[i])
+		}
+	}
+}
+
+func TestPackageModule_DottedPath(t *testing.T) {
+	info := &debug.BuildInfo{
+		GoVersion: "go1.25.1",
+		Main:      debug.Module{Path: "github.com/shop/app"},
+		Deps:      []*debug.Module{{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"}},
+	}
+
+	path, version := packageModule(symbolPackage("gopkg.in/yaml%2ev3.(*Node).Decode"), info)
+	if path != "gopkg.in/yaml.v3" || version != "v3.0.1" {
+		t.Errorf("packageModule() = %s %s, want gopkg.in/yaml.v3 v3.0.1", path, version)
+	}
+}
+
+func TestSizeIncreases(t *testing.T) {
+	previous := map[string]int64{"runtime": 500, "fmt": 100, "gone": 10}
+	current := map[string]int64{"runtime": 520, "fmt": 90, "net/http": 300}
+
+	got := sizeIncreases(current, previous)
+	want := []packageSize{{Path: "net/http", Size: 300}, {Path: "runtime", Size: 20}}
+	if len(got) != len(want) {
+		t.Fatalf("sizeIncreases() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sizeIncreases()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRegressed(t *testing.T) {
+	tests := []struct {
+		delta, before int64
+		want          bool
+	}{
+		{6, 100, true},
+		{5, 100, false},
+		{-50, 100, false},
+		{10, 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := regressed(tt.delta, tt.before); got != tt.want {
+			t.Errorf("regressed(%d, %d) = %v, want %v", tt.delta, tt.before, got, tt.want)
+		}
+	}
+	if got := growth(6, 100); got != "+6.0%" {
+		t.Errorf("growth() = %q, want +6.0%%", got)
+	}
+	if got := formatDelta(-2048); got != "-2.0 KB" {
+		t.Errorf("formatDelta() = %q, want -2.0 KB", got)
+	}
+}
+
+func TestRunBuild_Report(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module reportapp\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hi\") }\n"), 0644)
+
+	buildOutput = "reportapp"
+	buildOS = runtime.GOOS
+	buildArch = runtime.GOARCH
+	buildTags = ""
+	buildReport = true
+	defer func() {
+		buildOutput = ""
+		buildReport = false
+		buildCompare = ""
+	}()
+
+	// The first build has nothing to compare with, the second compares
+	// with the first
+	for range 2 {
+		if err := runBuild(nil, nil); err != nil {
+			t.Fatalf("runBuild() error = %v", err)
+		}
+	}
+
+	manifest, err := readManifest(manifestPath("reportapp"))
+	if err != nil {
+		t.Fatalf("readManifest() error = %v", err)
+	}
+	if manifest.Packages["main"] == 0 || manifest.Packages["fmt"] == 0 {
+		t.Errorf("manifest packages = %v, want main and fmt", manifest.Packages)
+	}
+	if _, err := os.Stat(reportBinary); !os.IsNotExist(err) {
+		t.Error("the report binary should be removed")
+	}
+
+	buildCompare = "missing.manifest.json"
+	if err := runBuild(nil, nil); err == nil {
+		t.Error("runBuild() should fail when --compare names a missing manifest")
+	}
+}
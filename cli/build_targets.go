@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// checksumsFile lists the sha256 of every archive in the dist directory
+const checksumsFile = "checksums.txt"
+
+// buildTarget is a platform to build for
+type buildTarget struct {
+	OS   string
+	Arch string
+}
+
+func (t buildTarget) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// binaryName returns the name of the app's binary for the target
+func (t buildTarget) binaryName(app string) string {
+	name := fmt.Sprintf("%s_%s_%s", app, t.OS, t.Arch)
+	if t.OS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// archiveName returns the name of the archive the binary is packaged in
+func (t buildTarget) archiveName(app string) string {
+	name := fmt.Sprintf("%s_%s_%s", app, t.OS, t.Arch)
+	if t.OS == "windows" {
+		return name + ".zip"
+	}
+	return name + ".tar.gz"
+}
+
+// parseTargets reads os/arch pairs, checking each is a platform the go
+// toolchain supports
+func parseTargets(values []string) ([]buildTarget, error) {
+	supported, err := supportedTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []buildTarget
+	seen := map[buildTarget]bool{}
+	for _, value := range values {
+		goos, goarch, ok := strings.Cut(strings.TrimSpace(value), "/")
+		target := buildTarget{OS: goos, Arch: goarch}
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid target %q (expected os/arch, e.g. linux/amd64)", value)
+		}
+		if !supported[target.String()] {
+			return nil, fmt.Errorf("unsupported target: %s (see go tool dist list)", target)
+		}
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// supportedTargets returns the platforms listed by go tool dist list
+func supportedTargets() (map[string]bool, error) {
+	out, err := exec.Command("go", "tool", "dist", "list").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list supported targets: %w", err)
+	}
+	supported := map[string]bool{}
+	for _, line := range strings.Fields(string(out)) {
+		supported[line] = true
+	}
+	return supported, nil
+}
+
+// targetResult is the outcome of building one target
+type targetResult struct {
+	target   buildTarget
+	manifest *buildManifest
+	archive  string
+	duration time.Duration
+	output   []byte // Compiler output, shown when the build fails
+	err      error
+}
+
+// runMatrixBuild builds and packages every target, at most buildJobs at
+// once, then writes the checksums and prints a summary. A failed target
+// does not stop the others.
+func runMatrixBuild(opts buildOptions, app string, targets []buildTarget) error {
+	if err := os.MkdirAll(buildDist, 0755); err != nil {
+		ui.Error(fmt.Sprintf("Failed to create %s: %v", buildDist, err))
+		return err
+	}
+
+	results := buildTargetsConcurrently(targets, max(buildJobs, 1), func(target buildTarget) targetResult {
+		return buildAndPackage(opts, app, target)
+	})
+
+	failed := 0
+	var archives []string
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			ui.Error(fmt.Sprintf("%s: %v", r.target, r.err))
+			if len(r.output) > 0 {
+				fmt.Print(string(r.output))
+			}
+			continue
+		}
+		archives = append(archives, r.archive)
+	}
+
+	if len(archives) > 0 {
+		if err := writeChecksums(buildDist, archives); err != nil {
+			ui.Error(fmt.Sprintf("Failed to write checksums: %v", err))
+			return err
+		}
+	}
+
+	ui.Newline()
+	printMatrixSummary(results)
+	ui.Newline()
+
+	if failed > 0 {
+		err := fmt.Errorf("%d of %d targets failed", failed, len(targets))
+		ui.Error(err.Error())
+		return err
+	}
+	ui.Success(fmt.Sprintf("Built %d targets into %s", len(targets), buildDist))
+	ui.Muted("Checksums: " + filepath.Join(buildDist, checksumsFile))
+	return nil
+}
+
+// buildTargetsConcurrently runs build for each target on a pool of jobs
+// workers. Results keep the order of targets.
+func buildTargetsConcurrently(targets []buildTarget, jobs int, build func(buildTarget) targetResult) []targetResult {
+	results := make([]targetResult, len(targets))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(jobs, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = build(targets[i])
+			}
+		}()
+	}
+	for i := range targets {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// buildAndPackage compiles one target into the dist directory and
+// archives it
+func buildAndPackage(opts buildOptions, app string, target buildTarget) targetResult {
+	start := time.Now()
+	result := targetResult{target: target}
+
+	var output bytes.Buffer
+	binary := filepath.Join(buildDist, target.binaryName(app))
+	result.manifest, result.err = compileTarget(opts, target, binary, &output)
+	if result.err == nil {
+		result.archive = filepath.Join(buildDist, target.archiveName(app))
+		inside := app
+		if target.OS == "windows" {
+			inside += ".exe"
+		}
+		result.err = archiveBinary(result.archive, binary, inside)
+	}
+
+	result.duration = time.Since(start)
+	result.output = output.Bytes()
+	if result.err == nil {
+		ui.Step(fmt.Sprintf("Built %s in %s", target, result.duration.Round(100*time.Millisecond)))
+	}
+	return result
+}
+
+// archiveBinary packages binary as name inside a .zip or .tar.gz,
+// chosen by the archive's extension
+func archiveBinary(archive, binary, name string) error {
+	info, err := os.Stat(binary)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(binary)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	f, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(archive, ".zip") {
+		err = writeZip(f, src, info, name)
+	} else {
+		err = writeTarGz(f, src, info, name)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func writeTarGz(w io.Writer, src io.Reader, info os.FileInfo, name string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	hdr := &tar.Header{Name: name, Mode: 0755, Size: info.Size(), ModTime: info.ModTime(), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, src); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeZip(w io.Writer, src io.Reader, info os.FileInfo, name string) error {
+	zw := zip.NewWriter(w)
+
+	hdr, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	hdr.Name = name
+	hdr.Method = zip.Deflate
+	hdr.SetMode(0755)
+
+	dst, err := zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeChecksums writes the sha256 of each archive in the format read by
+// sha256sum -c
+func writeChecksums(dir string, archives []string) error {
+	sorted := append([]string{}, archives...)
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	for _, archive := range sorted {
+		sum, _, err := fileDigest(archive)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, filepath.Base(archive))
+	}
+	return os.WriteFile(filepath.Join(dir, checksumsFile), []byte(b.String()), 0644)
+}
+
+// printMatrixSummary prints a row per target with its sizes and timing
+func printMatrixSummary(results []targetResult) {
+	var rows [][]string
+	for _, r := range results {
+		if r.err != nil {
+			rows = append(rows, []string{r.target.String(), "", "", r.duration.Round(100 * time.Millisecond).String(), ui.ErrorText("failed")})
+			continue
+		}
+
+		archiveSize := ""
+		if info, err := os.Stat(r.archive); err == nil {
+			archiveSize = formatSize(info.Size())
+		}
+		rows = append(rows, []string{
+			r.target.String(),
+			formatSize(r.manifest.Size),
+			archiveSize,
+			r.duration.Round(100 * time.Millisecond).String(),
+			filepath.Base(r.archive),
+		})
+	}
+	ui.Table([]string{"TARGET", "BINARY", "ARCHIVE", "TIME", "FILE"}, rows)
+}
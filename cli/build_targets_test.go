@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseTargets(t *testing.T) {
+	targets, err := parseTargets([]string{"linux/amd64", " darwin/arm64", "linux/amd64"})
+	if err != nil {
+		t.Fatalf("parseTargets() error = %v", err)
+	}
+	if len(targets) != 2 || targets[0].String() != "linux/amd64" || targets[1].String() != "darwin/arm64" {
+		t.Errorf("parseTargets() = %v, want linux/amd64 and darwin/arm64 once each", targets)
+	}
+
+	for _, value := range []string{"linux", "linux/", "/amd64", "plan10/amd64"} {
+		if _, err := parseTargets([]string{value}); err == nil {
+			t.Errorf("parseTargets(%q) should error", value)
+		}
+	}
+}
+
+func TestBuildTarget_Names(t *testing.T) {
+	linux := buildTarget{OS: "linux", Arch: "arm64"}
+	if got := linux.binaryName("shop"); got != "shop_linux_arm64" {
+		t.Errorf("binaryName() = %q", got)
+	}
+	if got := linux.archiveName("shop"); got != "shop_linux_arm64.tar.gz" {
+		t.Errorf("archiveName() = %q", got)
+	}
+
+	windows := buildTarget{OS: "windows", Arch: "amd64"}
+	if got := windows.binaryName("shop"); got != "shop_windows_amd64.exe" {
+		t.Errorf("binaryName() = %q", got)
+	}
+	if got := windows.archiveName("shop"); got != "shop_windows_amd64.zip" {
+		t.Errorf("archiveName() = %q", got)
+	}
+}
+
+func TestBuildTargetsConcurrently_BoundedAndOrdered(t *testing.T) {
+	var targets []buildTarget
+	for _, arch := range []string{"a", "b", "c", "d", "e", "f"} {
+		targets = append(targets, buildTarget{OS: "linux", Arch: arch})
+	}
+
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	results := buildTargetsConcurrently(targets, 2, func(target buildTarget) targetResult {
+		n := running.Add(1)
+		mu.Lock()
+		if n > peak.Load() {
+			peak.Store(n)
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return targetResult{target: target}
+	})
+
+	if peak.Load() > 2 {
+		t.Errorf("%d builds ran at once, want at most 2", peak.Load())
+	}
+	for i, r := range results {
+		if r.target != targets[i] {
+			t.Errorf("results[%d] = %s, want %s", i, r.target, targets[i])
+		}
+	}
+}
+
+func TestArchiveBinary(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "shop_linux_amd64")
+	os.WriteFile(binary, []byte("binary"), 0755)
+
+	tarball := filepath.Join(dir, "shop_linux_amd64.tar.gz")
+	if err := archiveBinary(tarball, binary, "shop"); err != nil {
+		t.Fatalf("archiveBinary() tar.gz error = %v", err)
+	}
+	f, _ := os.Open(tarball)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(tr)
+	if hdr.Name != "shop" || hdr.Mode != 0755 || string(content) != "binary" {
+		t.Errorf("tar entry = %s %o %q", hdr.Name, hdr.Mode, content)
+	}
+
+	zipFile := filepath.Join(dir, "shop_windows_amd64.zip")
+	if err := archiveBinary(zipFile, binary, "shop.exe"); err != nil {
+		t.Fatalf("archiveBinary() zip error = %v", err)
+	}
+	zr, err := zip.OpenReader(zipFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	if len(zr.File) != 1 || zr.File[0].Name != "shop.exe" {
+		t.Fatalf("zip entries = %v", zr.File)
+	}
+	rc, _ := zr.File[0].Open()
+	content, _ = io.ReadAll(rc)
+	rc.Close()
+	if string(content) != "binary" {
+		t.Errorf("zip content = %q", content)
+	}
+}
+
+func TestWriteChecksums(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "b.tar.gz"), []byte("b"), 0644)
+	os.WriteFile(filepath.Join(dir, "a.zip"), []byte("a"), 0644)
+
+	err := writeChecksums(dir, []string{filepath.Join(dir, "b.tar.gz"), filepath.Join(dir, "a.zip")})
+	if err != nil {
+		t.Fatalf("writeChecksums() error = %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, checksumsFile))
+	want := "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  a.zip\n" +
+		"3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d  b.tar.gz\n"
+	if string(data) != want {
+		t.Errorf("checksums = %q, want %q", data, want)
+	}
+}
+
+func TestRunBuild_Targets(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	defer func() { buildTargets, buildJobs, buildDist = nil, runtime.NumCPU(), "dist" }()
+
+	os.WriteFile("go.mod", []byte("module testbuild\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+
+	buildOutput = "shop"
+	buildTags = ""
+	buildTargets = []string{"linux/amd64", "windows/amd64"}
+	buildJobs = 2
+	buildDist = "dist"
+	if err := runBuild(nil, nil); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	for _, file := range []string{
+		"shop_linux_amd64", "shop_linux_amd64.manifest.json", "shop_linux_amd64.tar.gz",
+		"shop_windows_amd64.exe", "shop_windows_amd64.exe.manifest.json", "shop_windows_amd64.zip",
+		checksumsFile,
+	} {
+		if _, err := os.Stat(filepath.Join("dist", file)); err != nil {
+			t.Errorf("dist/%s not created", file)
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join("dist", checksumsFile))
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Errorf("checksums = %q, want one line per archive", data)
+	}
+}
+
+func TestRunBuild_TargetFailureReported(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	defer func() { buildTargets, buildJobs, buildDist = nil, runtime.NumCPU(), "dist" }()
+
+	// Only builds on linux
+	os.WriteFile("go.mod", []byte("module testbuild\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() { run() }\n"), 0644)
+	os.WriteFile("run_linux.go", []byte("package main\n\nfunc run() {}\n"), 0644)
+
+	buildOutput = "shop"
+	buildTags = ""
+	buildTargets = []string{"linux/amd64", "darwin/arm64"}
+	buildJobs = 2
+	buildDist = "dist"
+	err := runBuild(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 targets failed") {
+		t.Fatalf("runBuild() error = %v, want one failed target", err)
+	}
+	if _, err := os.Stat(filepath.Join("dist", "shop_linux_amd64.tar.gz")); err != nil {
+		t.Error("the target that built should still be packaged")
+	}
+}
@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -16,6 +21,16 @@ func TestBuildCmd_FlagDefaults(t *testing.T) {
 		{"os", runtime.GOOS},
 		{"arch", runtime.GOARCH},
 		{"tags", ""},
+		{"assets", "false"},
+		{"targets", "[]"},
+		{"jobs", strconv.Itoa(runtime.NumCPU())},
+		{"dist", "dist"},
+		{"cgo", "auto"},
+		{"cc", "[]"},
+		{"image", ""},
+		{"report", "false"},
+		{"compare", ""},
+		{"env-file", "[]"},
 	}
 
 	for _, tt := range tests {
@@ -191,3 +206,144 @@ func TestRunBuild_WindowsExeSuffix(t *testing.T) {
 		t.Error("Binary should have .exe suffix when targeting windows")
 	}
 }
+
+func TestRunBuild_StampsVersionAndWritesManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	os.WriteFile("go.mod", []byte("module testbuild\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte(`package main
+
+import "fmt"
+
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+func main() { fmt.Println(version, commit, date) }
+`), 0644)
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-qm", "initial"},
+		{"tag", "v1.2.0"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	commit, _ := exec.Command("git", "rev-parse", "HEAD").Output()
+
+	buildOutput = "stamped"
+	buildOS = runtime.GOOS
+	buildArch = runtime.GOARCH
+	buildTags = ""
+	if err := runBuild(nil, nil); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	output, err := exec.Command("./stamped").Output()
+	if err != nil {
+		t.Fatalf("running the binary: %v", err)
+	}
+	want := "v1.2.0 " + strings.TrimSpace(string(commit)) + " 2023-11-14T22:13:20Z\n"
+	if string(output) != want {
+		t.Errorf("stamped values = %q, want %q", output, want)
+	}
+
+	data, err := os.ReadFile("stamped.manifest.json")
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var manifest buildManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+
+	sum, size, _ := fileDigest("stamped")
+	if manifest.SHA256 != sum || manifest.Size != size {
+		t.Errorf("manifest digest = %s/%d, want %s/%d", manifest.SHA256, manifest.Size, sum, size)
+	}
+	if manifest.Version != "v1.2.0" || manifest.BuildTime != "2023-11-14T22:13:20Z" {
+		t.Errorf("manifest version = %s at %s", manifest.Version, manifest.BuildTime)
+	}
+	if !strings.HasPrefix(manifest.GoVersion, "go") {
+		t.Errorf("manifest go_version = %q", manifest.GoVersion)
+	}
+	if !slices.Contains(manifest.Flags, "-trimpath") {
+		t.Errorf("manifest flags = %v, want -trimpath", manifest.Flags)
+	}
+	if manifest.Assets != nil {
+		t.Error("no assets should be recorded without --assets")
+	}
+}
+
+func TestRunBuild_EmbedsAssets(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	defer func() { buildAssets = false }()
+
+	os.WriteFile("go.mod", []byte("module testbuild\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile("velocity.yaml", []byte(`build:
+  frontend: mkdir -p dist/js && echo "console.log(1)" > dist/js/app.js
+  assets: dist
+`), 0644)
+
+	buildOutput = "withassets"
+	buildOS = runtime.GOOS
+	buildArch = runtime.GOARCH
+	buildTags = ""
+	buildAssets = true
+	if err := runBuild(nil, nil); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	data, _ := os.ReadFile("withassets.manifest.json")
+	var manifest buildManifest
+	json.Unmarshal(data, &manifest)
+	if manifest.Assets == nil || manifest.Assets.Dir != "dist" || manifest.Assets.Files != 1 {
+		t.Errorf("manifest assets = %+v, want 1 file from dist", manifest.Assets)
+	}
+
+	// Deployed on its own, the binary writes the assets out
+	deploy := t.TempDir()
+	os.Rename("withassets", filepath.Join(deploy, "withassets"))
+	cmd := exec.Command("./withassets")
+	cmd.Dir = deploy
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("running the binary: %v\n%s", err, output)
+	}
+	content, err := os.ReadFile(filepath.Join(deploy, "dist", "js", "app.js"))
+	if err != nil || string(content) != "console.log(1)\n" {
+		t.Errorf("embedded asset = %q, %v", content, err)
+	}
+}
+
+func TestRunBuild_AssetsMissing(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	defer func() { buildAssets = false }()
+
+	os.WriteFile("go.mod", []byte("module testbuild\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+
+	buildOutput = "noassets"
+	buildOS = runtime.GOOS
+	buildArch = runtime.GOARCH
+	buildTags = ""
+	buildAssets = true
+	if err := runBuild(nil, nil); err == nil {
+		t.Error("runBuild() should error when there are no assets to embed")
+	}
+}
@@ -33,7 +33,20 @@ func initRootCmd() {
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(migrateCmd)
 	rootCmd.AddCommand(migrateFreshCmd)
+	rootCmd.AddCommand(migrateRollbackCmd)
+	rootCmd.AddCommand(migrateResetCmd)
+	rootCmd.AddCommand(migrateStatusCmd)
 	rootCmd.AddCommand(makeControllerCmd)
+	rootCmd.AddCommand(makeMigrationCmd)
+	rootCmd.AddCommand(makeModelCmd)
+	rootCmd.AddCommand(makeMiddlewareCmd)
+	rootCmd.AddCommand(makeRequestCmd)
+	rootCmd.AddCommand(makeJobCmd)
+	rootCmd.AddCommand(makeEventCmd)
+	rootCmd.AddCommand(makeListenerCmd)
+	rootCmd.AddCommand(makeDockerfileCmd)
+	rootCmd.AddCommand(stubPublishCmd)
+	rootCmd.AddCommand(routeListCmd)
 	rootCmd.AddCommand(keyGenerateCmd)
 }
 
@@ -41,7 +41,15 @@ func TestRootCmd_RegistersSubcommands(t *testing.T) {
 		commands[c.Name()] = true
 	}
 
-	required := []string{"serve", "build", "migrate", "migrate:fresh", "make:controller", "key:generate"}
+	required := []string{
+		"serve", "build",
+		"migrate", "migrate:fresh", "migrate:rollback", "migrate:reset", "migrate:status",
+		"make:controller", "make:migration", "make:model",
+		"make:middleware", "make:request", "make:job", "make:event", "make:listener",
+		"make:dockerfile",
+		"stub:publish", "route:list",
+		"key:generate",
+	}
 	for _, name := range required {
 		if !commands[name] {
 			t.Errorf("Missing required command: %s", name)
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/velocitykode/velocity-cli/internal/config"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// envExampleFile declares the keys an environment must define
+const envExampleFile = ".env.example"
+
+// envFiles returns the env files for appEnv, lowest precedence first:
+// .env, .env.<APP_ENV>, .env.local and then any --env-file in order
+func envFiles(appEnv string, extra []string) []string {
+	files := []string{".env"}
+	if appEnv != "" {
+		files = append(files, ".env."+appEnv)
+	}
+	files = append(files, ".env.local")
+	return append(files, extra...)
+}
+
+// loadEnvFiles merges the env files for appEnv, later files overriding
+// earlier ones. The standard files are optional; every extra file must
+// exist. It returns the merged values and the files that were read.
+func loadEnvFiles(appEnv string, extra []string) (map[string]string, []string, error) {
+	required := make(map[string]bool, len(extra))
+	for _, file := range extra {
+		required[file] = true
+	}
+
+	values := make(map[string]string)
+	var loaded []string
+	for _, file := range envFiles(appEnv, extra) {
+		fileValues, err := godotenv.Read(file)
+		if err != nil {
+			if os.IsNotExist(err) && !required[file] {
+				continue
+			}
+			return nil, nil, fmt.Errorf("load %s: %w", file, err)
+		}
+		for key, value := range fileValues {
+			values[key] = value
+		}
+		loaded = append(loaded, file)
+	}
+	return values, loaded, nil
+}
+
+// missingEnvKeys returns the keys declared in .env.example that neither
+// values nor the process environment define, sorted. Without an example
+// file nothing is required.
+func missingEnvKeys(values map[string]string) ([]string, error) {
+	example, err := godotenv.Read(envExampleFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", envExampleFile, err)
+	}
+
+	var missing []string
+	for key := range example {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		missing = append(missing, key)
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// processEnv returns the process environment with values added. Variables
+// already set in the process win over env files, and overrides, such as
+// those from command line flags, win over both.
+func processEnv(values map[string]string, overrides ...string) []string {
+	env := os.Environ()
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := os.LookupEnv(key); !ok {
+			env = append(env, key+"="+values[key])
+		}
+	}
+
+	// Later entries win when a process reads its environment, but drop
+	// the earlier ones so the result is unambiguous
+	for _, override := range overrides {
+		key, _, _ := strings.Cut(override, "=")
+		kept := env[:0]
+		for _, entry := range env {
+			if !strings.HasPrefix(entry, key+"=") {
+				kept = append(kept, entry)
+			}
+		}
+		env = append(kept, override)
+	}
+	return env
+}
+
+// loadEnvironment loads the env files for appEnv and returns the
+// environment for a child process along with the files read. Keys from
+// .env.example that are not set are reported as a *missingEnvError, with
+// the environment still returned.
+func loadEnvironment(appEnv string, extra []string, overrides ...string) ([]string, []string, error) {
+	values, loaded, err := loadEnvFiles(appEnv, extra)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env := processEnv(values, overrides...)
+	missing, err := missingEnvKeys(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(missing) > 0 {
+		return env, loaded, &missingEnvError{keys: missing}
+	}
+	return env, loaded, nil
+}
+
+// envWatch adds rules to watch that restart the server when an env file
+// it reads changes. The env files are added to a non-empty include list,
+// which would otherwise hide them from the rules.
+func envWatch(watch config.WatchConfig, appEnv string, extra []string) config.WatchConfig {
+	files := append(envFiles(appEnv, extra), envExampleFile)
+
+	rules := append([]config.WatchRule{}, watch.Rules...)
+	for _, file := range files {
+		rules = append(rules, config.WatchRule{Pattern: file, Action: config.WatchRestart})
+	}
+	watch.Rules = rules
+
+	if len(watch.Include) > 0 {
+		watch.Include = append(append([]string{}, watch.Include...), files...)
+	}
+	return watch
+}
+
+// missingEnvError reports keys declared in .env.example that are not set
+type missingEnvError struct {
+	keys []string
+}
+
+func (e *missingEnvError) Error() string {
+	return fmt.Sprintf("missing environment variables declared in %s: %s", envExampleFile, strings.Join(e.keys, ", "))
+}
+
+// reportEnvFiles lists the env files that were loaded
+func reportEnvFiles(loaded []string) {
+	if len(loaded) > 0 {
+		ui.Step(fmt.Sprintf("Environment: %s", strings.Join(loaded, ", ")))
+	}
+}
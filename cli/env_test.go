@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+func TestLoadEnvFiles_Precedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile(".env", []byte("A=env\nB=env\nC=env\nD=env\n"), 0644)
+	os.WriteFile(".env.staging", []byte("B=staging\nC=staging\nD=staging\n"), 0644)
+	os.WriteFile(".env.production", []byte("B=production\n"), 0644)
+	os.WriteFile(".env.local", []byte("C=local\nD=local\n"), 0644)
+	os.WriteFile("extra.env", []byte("D=extra\n"), 0644)
+
+	values, loaded, err := loadEnvFiles("staging", []string{"extra.env"})
+	if err != nil {
+		t.Fatalf("loadEnvFiles() error = %v", err)
+	}
+
+	want := map[string]string{"A": "env", "B": "staging", "C": "local", "D": "extra"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+	if wantLoaded := []string{".env", ".env.staging", ".env.local", "extra.env"}; !reflect.DeepEqual(loaded, wantLoaded) {
+		t.Errorf("loaded = %v, want %v", loaded, wantLoaded)
+	}
+}
+
+func TestLoadEnvFiles_MissingFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	values, loaded, err := loadEnvFiles("development", nil)
+	if err != nil || len(values) != 0 || len(loaded) != 0 {
+		t.Errorf("loadEnvFiles() without files = %v, %v, %v", values, loaded, err)
+	}
+
+	if _, _, err := loadEnvFiles("development", []string{"missing.env"}); err == nil {
+		t.Error("loadEnvFiles() should fail when an --env-file does not exist")
+	}
+}
+
+func TestLoadEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	t.Setenv("VELOCITY_TEST_SHELL", "shell")
+	os.WriteFile(".env.example", []byte("APP_NAME=\nDB_HOST=\nVELOCITY_TEST_SHELL=\n"), 0644)
+	os.WriteFile(".env", []byte("APP_NAME=blog\nVELOCITY_TEST_SHELL=file\nAPP_PORT=1\n"), 0644)
+
+	_, _, err := loadEnvironment("development", nil)
+	var missing *missingEnvError
+	if !errors.As(err, &missing) || !reflect.DeepEqual(missing.keys, []string{"DB_HOST"}) {
+		t.Fatalf("loadEnvironment() error = %v, want DB_HOST missing", err)
+	}
+
+	os.WriteFile(".env.local", []byte("DB_HOST=localhost\n"), 0644)
+	env, _, err := loadEnvironment("development", nil, "APP_PORT=4000")
+	if err != nil {
+		t.Fatalf("loadEnvironment() error = %v", err)
+	}
+
+	got := map[string][]string{}
+	for _, entry := range env {
+		for _, key := range []string{"APP_NAME", "DB_HOST", "VELOCITY_TEST_SHELL", "APP_PORT"} {
+			if len(entry) > len(key) && entry[:len(key)+1] == key+"=" {
+				got[key] = append(got[key], entry[len(key)+1:])
+			}
+		}
+	}
+	want := map[string][]string{
+		"APP_NAME":            {"blog"},
+		"DB_HOST":             {"localhost"},
+		"VELOCITY_TEST_SHELL": {"shell"},
+		"APP_PORT":            {"4000"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("environment = %v, want %v", got, want)
+	}
+}
+
+func TestEnvWatch(t *testing.T) {
+	matcher := newWatchMatcher(envWatch(config.WatchConfig{}, "development", []string{"secrets.env"}))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{".env", config.WatchRestart},
+		{".env.development", config.WatchRestart},
+		{".env.local", config.WatchRestart},
+		{".env.example", config.WatchRestart},
+		{"secrets.env", config.WatchRestart},
+		{".env.production", ""},
+	}
+	for _, tt := range tests {
+		if got := matcher.action(tt.name); got != tt.want {
+			t.Errorf("action(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnvWatch_WithInclude(t *testing.T) {
+	watch := config.WatchConfig{Include: []string{"app", "routes"}}
+	matcher := newWatchMatcher(envWatch(watch, "development", nil))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{".env", config.WatchRestart},
+		{".env.development", config.WatchRestart},
+		{"app/models/user.go", config.WatchRebuild},
+		{"main.go", ""},
+	}
+	for _, tt := range tests {
+		if got := matcher.action(tt.name); got != tt.want {
+			t.Errorf("action(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if len(watch.Include) != 2 {
+		t.Errorf("envWatch() changed the caller's include list: %v", watch.Include)
+	}
+}
@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/stubs"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// artifact describes a file type that a make:* command scaffolds
+type artifact struct {
+	Kind       string // Human readable name, e.g. "middleware"
+	Dir        string // Base output directory
+	Package    string // Package name when the name is not nested
+	Stub       string // Stub path, overridable from the project stubs/ directory
+	Suffix     string // Type suffix stripped from the given name, e.g. "Request"
+	FileSuffix string // Appended to the snake_case file name, e.g. "_request"
+}
+
+// artifactTarget is where a generator name resolves to
+type artifactTarget struct {
+	Name    string // PascalCase name without the artifact suffix
+	Package string
+	Path    string
+}
+
+var (
+	middlewareArtifact = artifact{
+		Kind:    "middleware",
+		Dir:     "app/middleware",
+		Package: "middleware",
+		Stub:    "app/middleware/make.go.stub",
+		Suffix:  "Middleware",
+	}
+	requestArtifact = artifact{
+		Kind:       "request",
+		Dir:        "app/http/requests",
+		Package:    "requests",
+		Stub:       "app/http/requests/request.go.stub",
+		Suffix:     "Request",
+		FileSuffix: "_request",
+	}
+	jobArtifact = artifact{
+		Kind:    "job",
+		Dir:     "app/jobs",
+		Package: "jobs",
+		Stub:    "app/jobs/job.go.stub",
+	}
+	eventArtifact = artifact{
+		Kind:    "event",
+		Dir:     "app/events",
+		Package: "events",
+		Stub:    "app/events/event.go.stub",
+	}
+	listenerArtifact = artifact{
+		Kind:    "listener",
+		Dir:     "app/listeners",
+		Package: "listeners",
+		Stub:    "app/listeners/listener.go.stub",
+	}
+)
+
+// resolve maps a name like "Admin/Dashboard" to its type name, package and
+// output path. Directory parts are lowercased for conventional Go packages.
+func (a artifact) resolve(name string) (artifactTarget, error) {
+	var parts []string
+	for _, part := range strings.Split(filepath.ToSlash(name), "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return artifactTarget{}, fmt.Errorf("invalid %s name: %q", a.Kind, name)
+	}
+
+	last := parts[len(parts)-1]
+	if a.Suffix != "" {
+		last = strings.TrimSuffix(last, a.Suffix)
+		last = strings.TrimSuffix(last, strings.ToLower(a.Suffix))
+	}
+
+	typeName := toPascalCase(last)
+	if typeName == "" {
+		return artifactTarget{}, fmt.Errorf("invalid %s name: %q", a.Kind, name)
+	}
+
+	target := artifactTarget{Name: typeName, Package: a.Package}
+	dirs := parts[:len(parts)-1]
+	for i := range dirs {
+		dirs[i] = strings.ToLower(dirs[i])
+	}
+	if len(dirs) > 0 {
+		target.Package = dirs[len(dirs)-1]
+	}
+
+	filename := toSnakeCase(typeName) + a.FileSuffix + ".go"
+	target.Path = filepath.Join(a.Dir, filepath.Join(dirs...), filename)
+	return target, nil
+}
+
+// generate renders the artifact stub for name and returns the written path
+func (a artifact) generate(created *generatedFiles, name string, force bool) (string, error) {
+	target, err := a.resolve(name)
+	if err != nil {
+		ui.Error(err.Error())
+		return "", err
+	}
+
+	data := map[string]interface{}{
+		"Package": target.Package,
+		"Name":    target.Name,
+	}
+	return writeStub(created, a.Stub, target.Path, a.Kind, data, force)
+}
+
+// newMakeCommand builds the make:<kind> command for a simple artifact
+func newMakeCommand(a artifact, examples ...string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     fmt.Sprintf("make:%s [name]", a.Kind),
+		Short:   fmt.Sprintf("Create a new %s", a.Kind),
+		Long:    fmt.Sprintf("Create a new %s in the %s directory.", a.Kind, a.Dir),
+		Example: "  velocity " + strings.Join(examples, "\n  velocity "),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				ui.Error(a.Kind + " name is required")
+				ui.Newline()
+				ui.Muted("Usage:")
+				ui.Muted(fmt.Sprintf("  velocity make:%s [name]", a.Kind))
+				ui.Newline()
+				ui.Muted("Examples:")
+				for _, example := range examples {
+					ui.Muted("  velocity " + example)
+				}
+				return fmt.Errorf("") // Return empty error to exit with code 1
+			}
+			if len(args) > 1 {
+				return fmt.Errorf("too many arguments, expected only %s name", a.Kind)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ui.Header("make:" + a.Kind)
+
+			force, _ := cmd.Flags().GetBool("force")
+
+			created := &generatedFiles{}
+			outputPath, err := a.generate(created, args[0], force)
+			if err != nil {
+				created.rollback()
+				return err
+			}
+
+			ui.Success(fmt.Sprintf("Created: %s", outputPath))
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("force", false, fmt.Sprintf("Overwrite the %s if it already exists", a.Kind))
+	return cmd
+}
+
+// writeStub renders stubName into outputPath. Existing files are only
+// replaced when force is set.
+func writeStub(created *generatedFiles, stubName, outputPath, kind string, data interface{}, force bool) (string, error) {
+	if _, err := os.Stat(outputPath); err == nil && !force {
+		ui.Error(fmt.Sprintf("%s already exists: %s", toPascalCase(kind), outputPath))
+		ui.Muted("Use --force to overwrite it")
+		return "", fmt.Errorf("%s already exists", kind)
+	}
+
+	if err := created.mkdirAll(filepath.Dir(outputPath)); err != nil {
+		ui.Error(fmt.Sprintf("Failed to create directory: %v", err))
+		return "", err
+	}
+
+	content, err := renderStub(stubName, data)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to render %s: %v", kind, err))
+		return "", err
+	}
+
+	if err := created.writeFile(outputPath, content); err != nil {
+		ui.Error(fmt.Sprintf("Failed to write file: %v", err))
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+// generatedFiles records the files and directories created by a generator
+// so a multi-step command can undo its work when a later step fails.
+type generatedFiles struct {
+	files     []string
+	dirs      []string
+	originals map[string][]byte // Previous content of overwritten files
+}
+
+// mkdirAll creates dir and its parents, remembering every directory it created
+func (g *generatedFiles) mkdirAll(dir string) error {
+	var missing []string
+	for d := filepath.Clean(dir); d != "." && d != string(filepath.Separator); d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// Record parents before children so rollback can remove them in reverse
+	for i := len(missing) - 1; i >= 0; i-- {
+		g.dirs = append(g.dirs, missing[i])
+	}
+	return nil
+}
+
+// writeFile writes a file and remembers it for rollback. When the file
+// already exists its previous content is kept so rollback can restore it.
+func (g *generatedFiles) writeFile(path string, content []byte) error {
+	if previous, err := os.ReadFile(path); err == nil {
+		if g.originals == nil {
+			g.originals = make(map[string][]byte)
+		}
+		g.originals[path] = previous
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	g.files = append(g.files, path)
+	return nil
+}
+
+// rollback removes everything created so far, newest first, restores
+// overwritten files and returns the paths it undid
+func (g *generatedFiles) rollback() []string {
+	var removed []string
+	for i := len(g.files) - 1; i >= 0; i-- {
+		path := g.files[i]
+		if previous, ok := g.originals[path]; ok {
+			if err := os.WriteFile(path, previous, 0644); err == nil {
+				removed = append(removed, path)
+			}
+			continue
+		}
+		if err := os.Remove(path); err == nil {
+			removed = append(removed, path)
+		}
+	}
+	for i := len(g.dirs) - 1; i >= 0; i-- {
+		os.Remove(g.dirs[i]) // Only succeeds if still empty
+	}
+
+	g.files = nil
+	g.dirs = nil
+	g.originals = nil
+	return removed
+}
+
+// renderStub executes the named stub with data. A project-local copy in
+// stubs/ takes precedence over the embedded stub.
+func renderStub(name string, data interface{}) ([]byte, error) {
+	content, _, err := stubs.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(name)).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pluralize returns the English plural of a lowercase word
+func pluralize(word string) string {
+	switch {
+	case word == "":
+		return word
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
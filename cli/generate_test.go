@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"post", "posts"},
+		{"category", "categories"},
+		{"day", "days"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"match", "matches"},
+		{"blog_post", "blog_posts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := pluralize(tt.input)
+			if got != tt.expected {
+				t.Errorf("pluralize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGeneratedFiles_Rollback(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	// Pre-existing directory and file must survive rollback
+	os.MkdirAll("app", 0755)
+	os.WriteFile("app/keep.go", []byte("package app"), 0644)
+
+	created := &generatedFiles{}
+	if err := created.mkdirAll("app/models/admin"); err != nil {
+		t.Fatalf("mkdirAll() error = %v", err)
+	}
+	if err := created.writeFile("app/models/admin/post.go", []byte("package admin")); err != nil {
+		t.Fatalf("writeFile() error = %v", err)
+	}
+
+	removed := created.rollback()
+	if len(removed) != 1 || removed[0] != "app/models/admin/post.go" {
+		t.Errorf("rollback() removed = %v, want the generated file", removed)
+	}
+
+	if _, err := os.Stat("app/models"); !os.IsNotExist(err) {
+		t.Error("rollback() should remove directories it created")
+	}
+	if _, err := os.Stat(filepath.Join("app", "keep.go")); err != nil {
+		t.Error("rollback() should not touch pre-existing files")
+	}
+}
+
+func TestArtifactResolve(t *testing.T) {
+	tests := []struct {
+		artifact artifact
+		input    string
+		name     string
+		pkg      string
+		path     string
+	}{
+		{middlewareArtifact, "EnsureAdmin", "EnsureAdmin", "middleware", "app/middleware/ensure_admin.go"},
+		{middlewareArtifact, "ensure_admin_middleware", "EnsureAdmin", "middleware", "app/middleware/ensure_admin.go"},
+		{requestArtifact, "StorePostRequest", "StorePost", "requests", "app/http/requests/store_post_request.go"},
+		{requestArtifact, "Admin/UpdateUser", "UpdateUser", "admin", "app/http/requests/admin/update_user_request.go"},
+		{jobArtifact, "Billing/Invoices/charge-card", "ChargeCard", "invoices", "app/jobs/billing/invoices/charge_card.go"},
+		{controllerArtifact, "Admin/DashboardController", "Dashboard", "admin", "app/http/controllers/admin/dashboard_controller.go"},
+		{eventArtifact, "/UserRegistered/", "UserRegistered", "events", "app/events/user_registered.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := tt.artifact.resolve(tt.input)
+			if err != nil {
+				t.Fatalf("resolve(%q) error = %v", tt.input, err)
+			}
+			if got.Name != tt.name || got.Package != tt.pkg || got.Path != filepath.FromSlash(tt.path) {
+				t.Errorf("resolve(%q) = %+v, want {%s %s %s}", tt.input, got, tt.name, tt.pkg, tt.path)
+			}
+		})
+	}
+}
+
+func TestArtifactResolve_InvalidName(t *testing.T) {
+	for _, input := range []string{"", "/", "Admin/Middleware"} {
+		if _, err := middlewareArtifact.resolve(input); err == nil {
+			t.Errorf("resolve(%q) should error", input)
+		}
+	}
+}
+
+func TestMakeCommands_GenerateValidGo(t *testing.T) {
+	tests := []struct {
+		cmdName string
+		input   string
+		path    string
+		want    string
+	}{
+		{"make:middleware", "EnsureAdmin", "app/middleware/ensure_admin.go", "func EnsureAdminMiddleware("},
+		{"make:request", "Admin/StorePost", "app/http/requests/admin/store_post_request.go", "package admin"},
+		{"make:job", "SendWelcomeEmail", "app/jobs/send_welcome_email.go", `queue.Register("*jobs.SendWelcomeEmail"`},
+		{"make:event", "UserRegistered", "app/events/user_registered.go", "type UserRegistered struct{}"},
+		{"make:listener", "NotifyAdmins", "app/listeners/notify_admins.go", "func (NotifyAdmins) Handle("},
+	}
+
+	rootCmd = nil
+	initRootCmd()
+
+	for _, tt := range tests {
+		t.Run(tt.cmdName, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			originalDir, _ := os.Getwd()
+			os.Chdir(tmpDir)
+			defer os.Chdir(originalDir)
+
+			cmd, _, err := rootCmd.Find([]string{tt.cmdName})
+			if err != nil || cmd.Name() != tt.cmdName {
+				t.Fatalf("Command %s not registered", tt.cmdName)
+			}
+
+			if err := cmd.RunE(cmd, []string{tt.input}); err != nil {
+				t.Fatalf("%s error = %v", tt.cmdName, err)
+			}
+
+			content, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatalf("File not created at %s", tt.path)
+			}
+			if !strings.Contains(string(content), tt.want) {
+				t.Errorf("Generated file should contain %q", tt.want)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), tt.path, content, 0); err != nil {
+				t.Errorf("Generated file is not valid Go: %v", err)
+			}
+		})
+	}
+}
+
+func TestMakeCommand_Force(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	cmd := newMakeCommand(eventArtifact, "make:event UserRegistered")
+	os.MkdirAll("app/events", 0755)
+	os.WriteFile("app/events/user_registered.go", []byte("existing"), 0644)
+
+	if err := cmd.RunE(cmd, []string{"UserRegistered"}); err == nil {
+		t.Fatal("make:event should error when the file exists without --force")
+	}
+
+	cmd.Flags().Set("force", "true")
+	if err := cmd.RunE(cmd, []string{"UserRegistered"}); err != nil {
+		t.Fatalf("make:event --force error = %v", err)
+	}
+
+	content, _ := os.ReadFile("app/events/user_registered.go")
+	if string(content) == "existing" {
+		t.Error("--force should overwrite the existing file")
+	}
+}
+
+func TestMakeCommand_NoArgs(t *testing.T) {
+	cmd := newMakeCommand(jobArtifact, "make:job SendWelcomeEmail")
+	if err := cmd.Args(cmd, []string{}); err == nil {
+		t.Error("Args validation should error with no args")
+	}
+	if err := cmd.Args(cmd, []string{"A", "B"}); err == nil {
+		t.Error("Args validation should error with too many args")
+	}
+}
+
+func TestGeneratedFiles_RollbackRestoresOverwritten(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("post.go", []byte("original"), 0644)
+
+	created := &generatedFiles{}
+	created.writeFile("post.go", []byte("generated"))
+	created.rollback()
+
+	content, _ := os.ReadFile("post.go")
+	if string(content) != "original" {
+		t.Errorf("rollback() should restore overwritten content, got %q", content)
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+	"golang.org/x/term"
+)
+
+// keyBinding is a key that runs an action while serve is running
+type keyBinding struct {
+	key  string // As reported by tea.KeyMsg.String()
+	help string
+	run  func()
+}
+
+// keyModel dispatches keypresses to their bindings. It renders nothing,
+// so output from the app and the CLI flows around it untouched.
+type keyModel struct {
+	bindings []keyBinding
+}
+
+func (m keyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m keyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		for _, b := range m.bindings {
+			if b.key == key.String() {
+				b.run()
+				break
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m keyModel) View() string {
+	return ""
+}
+
+// keyInput hides that stdin is a terminal from bubbletea, which would
+// otherwise switch it to raw mode
+type keyInput struct {
+	io.Reader
+}
+
+// watchKeys runs the bindings for keys pressed on the terminal, without
+// waiting for Enter, and prints a legend of them. It returns a function
+// that stops reading and restores the terminal. When stdin is not a
+// terminal nothing is read.
+func watchKeys(bindings []keyBinding) (stop func()) {
+	if len(bindings) == 0 || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return func() {}
+	}
+
+	// stty keeps output processing intact, unlike a fully raw terminal,
+	// so logs printed meanwhile still start at the left margin. Ctrl-C
+	// still raises SIGINT.
+	saved, err := stty("-g")
+	if err != nil {
+		return func() {}
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return func() {}
+	}
+
+	program := tea.NewProgram(keyModel{bindings: bindings},
+		tea.WithInput(keyInput{os.Stdin}),
+		tea.WithOutput(io.Discard),
+		tea.WithoutRenderer(),
+		tea.WithoutSignalHandler(),
+	)
+	ui.Muted("Keys: " + keyHelp(bindings))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		program.Run()
+	}()
+
+	return func() {
+		program.Kill()
+		<-done
+		stty(strings.TrimSpace(saved))
+	}
+}
+
+// keyHelp lists the bindings as a one-line legend
+func keyHelp(bindings []keyBinding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		parts = append(parts, b.key+" "+b.help)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// stty runs stty on the terminal attached to stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
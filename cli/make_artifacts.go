@@ -0,0 +1,26 @@
+package cli
+
+// Generators for artifacts that only need a name. Each one shares the
+// artifact engine in generate.go for naming, nesting and --force handling.
+var (
+	makeMiddlewareCmd = newMakeCommand(middlewareArtifact,
+		"make:middleware EnsureAdmin",
+		"make:middleware Admin/AuditLog",
+	)
+	makeRequestCmd = newMakeCommand(requestArtifact,
+		"make:request StorePost",
+		"make:request Admin/UpdateUser",
+	)
+	makeJobCmd = newMakeCommand(jobArtifact,
+		"make:job SendWelcomeEmail",
+		"make:job Billing/ChargeInvoice",
+	)
+	makeEventCmd = newMakeCommand(eventArtifact,
+		"make:event UserRegistered",
+		"make:event Orders/OrderShipped",
+	)
+	makeListenerCmd = newMakeCommand(listenerArtifact,
+		"make:listener SendWelcomeEmail",
+		"make:listener Orders/NotifyCustomer",
+	)
+)
@@ -1,22 +1,18 @@
 package cli
 
 import (
-	"bytes"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
-	"text/template"
 	"unicode"
 
 	"github.com/spf13/cobra"
-	"github.com/velocitykode/velocity-cli/internal/stubs"
 	"github.com/velocitykode/velocity-cli/internal/ui"
 )
 
 var (
 	makeControllerResource bool
 	makeControllerAPI      bool
+	makeControllerForce    bool
 )
 
 var makeControllerCmd = &cobra.Command{
@@ -47,99 +43,49 @@ var makeControllerCmd = &cobra.Command{
 func init() {
 	makeControllerCmd.Flags().BoolVarP(&makeControllerResource, "resource", "r", false, "Generate a resource controller with CRUD methods")
 	makeControllerCmd.Flags().BoolVar(&makeControllerAPI, "api", false, "Generate an API controller (JSON responses)")
+	makeControllerCmd.Flags().BoolVar(&makeControllerForce, "force", false, "Overwrite the controller if it already exists")
 }
 
 func runMakeController(cmd *cobra.Command, args []string) error {
-	name := args[0]
-
 	ui.Header("make:controller")
 
-	// Normalize name
-	controllerName := toControllerName(name)
-
-	// Determine package and path
-	packageName := "controllers"
-	outputDir := "app/http/controllers"
-
-	// Check if name contains path separator
-	if strings.Contains(name, "/") {
-		parts := strings.Split(name, "/")
-		controllerName = toControllerName(parts[len(parts)-1])
-		packageName = strings.ToLower(parts[len(parts)-2])
-		// Lowercase all path parts for conventional Go package directories
-		for i := range parts[:len(parts)-1] {
-			parts[i] = strings.ToLower(parts[i])
-		}
-		outputDir = filepath.Join("app/http/controllers", filepath.Join(parts[:len(parts)-1]...))
-	}
-
-	// Create directory if needed
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		ui.Error(fmt.Sprintf("Failed to create directory: %v", err))
+	created := &generatedFiles{}
+	outputPath, err := makeController(created, args[0], makeControllerResource, makeControllerAPI, makeControllerForce)
+	if err != nil {
+		created.rollback()
 		return err
 	}
 
-	// Generate filename
-	filename := toSnakeCase(controllerName) + "_controller.go"
-	outputPath := filepath.Join(outputDir, filename)
-
-	// Check if file exists
-	if _, err := os.Stat(outputPath); err == nil {
-		ui.Error(fmt.Sprintf("Controller already exists: %s", outputPath))
-		return fmt.Errorf("controller already exists")
-	}
-
-	// Load stub
-	stubContent, err := stubs.Get("app/http/controllers/controller.go.stub")
-	if err != nil {
-		// Fallback to basic template if stub not found
-		stubContent = []byte(`package {{ .Package }}
-
-import "github.com/velocitykode/velocity/pkg/router"
+	ui.Success(fmt.Sprintf("Created: %s", outputPath))
+	return nil
+}
 
-func {{ .ControllerName }}(ctx *router.Context) error {
-	return ctx.String(200, "{{ .ControllerName }}")
+// controllerArtifact places controllers under app/http/controllers
+var controllerArtifact = artifact{
+	Kind:       "controller",
+	Dir:        "app/http/controllers",
+	Package:    "controllers",
+	Stub:       "app/http/controllers/controller.go.stub",
+	Suffix:     "Controller",
+	FileSuffix: "_controller",
 }
-`)
-	}
 
-	// Parse and execute template
-	tmpl, err := template.New("controller").Parse(string(stubContent))
+// makeController writes a controller for name and returns its path
+func makeController(created *generatedFiles, name string, resource, api, force bool) (string, error) {
+	target, err := controllerArtifact.resolve(name)
 	if err != nil {
-		ui.Error(fmt.Sprintf("Failed to parse template: %v", err))
-		return err
+		ui.Error(err.Error())
+		return "", err
 	}
 
 	data := map[string]interface{}{
-		"Package":        packageName,
-		"ControllerName": controllerName,
-		"Resource":       makeControllerResource,
-		"API":            makeControllerAPI,
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		ui.Error(fmt.Sprintf("Failed to execute template: %v", err))
-		return err
-	}
-
-	// Write file
-	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
-		ui.Error(fmt.Sprintf("Failed to write file: %v", err))
-		return err
+		"Package":        target.Package,
+		"Name":           target.Name,
+		"ControllerName": target.Name,
+		"Resource":       resource,
+		"API":            api,
 	}
-
-	ui.Success(fmt.Sprintf("Created: %s", outputPath))
-	return nil
-}
-
-func toControllerName(name string) string {
-	// Remove "Controller" suffix if present
-	name = strings.TrimSuffix(name, "Controller")
-	name = strings.TrimSuffix(name, "controller")
-
-	// Convert to PascalCase
-	return toPascalCase(name)
+	return writeStub(created, controllerArtifact.Stub, target.Path, controllerArtifact.Kind, data, force)
 }
 
 func toPascalCase(s string) string {
@@ -6,29 +6,6 @@ import (
 	"testing"
 )
 
-func TestToControllerName(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"user", "User"},
-		{"User", "User"},
-		{"UserController", "User"},
-		{"userController", "User"},
-		{"user_profile", "UserProfile"},
-		{"user-profile", "UserProfile"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			got := toControllerName(tt.input)
-			if got != tt.expected {
-				t.Errorf("toControllerName(%q) = %q, want %q", tt.input, got, tt.expected)
-			}
-		})
-	}
-}
-
 func TestToPascalCase(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -249,3 +226,26 @@ func TestRunMakeController_WriteError(t *testing.T) {
 		t.Error("runMakeController() should error when cannot write file")
 	}
 }
+
+func TestRunMakeController_Force(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.MkdirAll("app/http/controllers", 0755)
+	os.WriteFile("app/http/controllers/user_controller.go", []byte("existing"), 0644)
+
+	makeControllerForce = true
+	defer func() { makeControllerForce = false }()
+
+	err := runMakeController(nil, []string{"User"})
+	if err != nil {
+		t.Fatalf("runMakeController() with --force error = %v", err)
+	}
+
+	content, _ := os.ReadFile("app/http/controllers/user_controller.go")
+	if !strings.Contains(string(content), "func User(") {
+		t.Error("--force should overwrite the existing controller")
+	}
+}
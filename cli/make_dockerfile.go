@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/config"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+	"golang.org/x/mod/modfile"
+)
+
+var makeDockerfileForce bool
+
+var makeDockerfileCmd = &cobra.Command{
+	Use:   "make:dockerfile",
+	Short: "Create a Dockerfile and .dockerignore for the project",
+	Long: `Create a multi-stage Dockerfile and a .dockerignore tailored to the project.
+
+The Go version comes from go.mod. Dependencies that need cgo, such as the
+SQLite driver, switch the build to a Debian image with cgo on; otherwise the
+binary is static and runs on Alpine. A project with a frontend build script
+gets a node (or bun, with a bun lockfile) stage whose output is copied into
+the image. When cmd/velocity exists it is built alongside the app and its
+migrate command runs before the server starts. SQLite projects keep their
+database in a volume.
+
+Both files are rendered from stubs that can be published with stub:publish.`,
+	Example: "  velocity make:dockerfile\n  velocity make:dockerfile --force",
+	Args:    cobra.NoArgs,
+	RunE:    runMakeDockerfile,
+}
+
+func init() {
+	makeDockerfileCmd.Flags().BoolVar(&makeDockerfileForce, "force", false, "Overwrite the Dockerfile and .dockerignore if they already exist")
+}
+
+// Docker files a project is built with
+const (
+	dockerfilePath   = "Dockerfile"
+	dockerignorePath = ".dockerignore"
+	defaultAppPort   = "4000"
+)
+
+// dockerFiles maps the generated files to their stubs
+var dockerFiles = []struct {
+	Path string
+	Stub string
+}{
+	{dockerfilePath, "docker/Dockerfile.stub"},
+	{dockerignorePath, "docker/dockerignore.stub"},
+}
+
+// databaseDrivers maps driver modules to the database they connect to
+var databaseDrivers = map[string]string{
+	"github.com/lib/pq":              "postgres",
+	"github.com/jackc/pgx/v5":        "postgres",
+	"github.com/go-sql-driver/mysql": "mysql",
+	"github.com/mattn/go-sqlite3":    "sqlite",
+	"modernc.org/sqlite":             "sqlite",
+}
+
+// runtimeDirs are copied into the image when the project has them
+var runtimeDirs = []string{"public", "resources/views"}
+
+// dockerfileData holds the template data for the docker stubs
+type dockerfileData struct {
+	Name          string
+	GoVersion     string
+	CGO           bool
+	Database      string
+	Frontend      string
+	FrontendImage string
+	Install       string
+	Lockfile      string
+	Assets        string
+	Dirs          []string
+	Migrations    bool
+	Port          string
+
+	cgoModules []string
+	replaced   []string // Modules replaced with directories outside the project
+}
+
+func runMakeDockerfile(cmd *cobra.Command, args []string) error {
+	ui.Header("make:dockerfile")
+
+	data, err := projectDockerfileData()
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	reportDockerfileData(data)
+
+	created := &generatedFiles{}
+	for _, file := range dockerFiles {
+		if _, err := writeStub(created, file.Stub, file.Path, file.Path, data, makeDockerfileForce); err != nil {
+			created.rollback()
+			return err
+		}
+	}
+
+	ui.Newline()
+	for _, file := range dockerFiles {
+		ui.Success(fmt.Sprintf("Created: %s", file.Path))
+	}
+	return nil
+}
+
+// projectDockerfileData inspects the project in the current directory
+func projectDockerfileData() (dockerfileData, error) {
+	goMod, err := os.ReadFile("go.mod")
+	if err != nil {
+		return dockerfileData{}, fmt.Errorf("go.mod not found, run this in a Velocity project")
+	}
+	file, err := modfile.Parse("go.mod", goMod, nil)
+	if err != nil {
+		return dockerfileData{}, err
+	}
+	project, err := config.LoadProject(".")
+	if err != nil {
+		return dockerfileData{}, err
+	}
+
+	cwd, _ := os.Getwd()
+	data := dockerfileData{
+		Name:      filepath.Base(cwd),
+		GoVersion: "1",
+		Assets:    assetsDir(project.Build),
+		Port:      defaultAppPort,
+	}
+	if file.Go != nil {
+		data.GoVersion = goMinorVersion(file.Go.Version)
+	}
+
+	for _, r := range file.Require {
+		if driver, ok := databaseDrivers[r.Mod.Path]; ok && data.Database == "" {
+			data.Database = driver
+		}
+	}
+	for _, r := range file.Replace {
+		if r.New.Version == "" && !filepath.IsLocal(r.New.Path) {
+			data.replaced = append(data.replaced, r.Old.Path+" => "+r.New.Path)
+		}
+	}
+
+	env, _, err := loadEnvFiles("production", nil)
+	if err != nil {
+		env = map[string]string{}
+	}
+	if example, err := godotenv.Read(envExampleFile); err == nil {
+		for key, value := range example {
+			if _, ok := env[key]; !ok {
+				env[key] = value
+			}
+		}
+	}
+	if data.Database == "" {
+		data.Database = env["DB_CONNECTION"]
+	}
+	if env["APP_PORT"] != "" {
+		data.Port = env["APP_PORT"]
+	}
+
+	if data.cgoModules, err = cgoModules(os.Environ()); err != nil {
+		data.cgoModules = knownCgoRequirements()
+	}
+	data.CGO = len(data.cgoModules) > 0
+
+	if frontendCommand(project.Build) != "" {
+		dockerFrontend(&data, project.Build)
+	}
+
+	for _, dir := range runtimeDirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			data.Dirs = append(data.Dirs, dir)
+		}
+	}
+	if info, err := os.Stat(filepath.Join("cmd", "velocity")); err == nil && info.IsDir() {
+		data.Migrations = true
+	}
+	return data, nil
+}
+
+// dockerFrontend picks the image and commands of the frontend stage from
+// the project's lockfile
+func dockerFrontend(data *dockerfileData, cfg config.BuildConfig) {
+	runner := "npm"
+	data.FrontendImage = "node:22-alpine"
+	data.Install = "npm install"
+	for _, lockfile := range []string{"bun.lock", "bun.lockb", "package-lock.json"} {
+		if _, err := os.Stat(lockfile); err != nil {
+			continue
+		}
+		data.Lockfile = lockfile
+		if strings.HasPrefix(lockfile, "bun") {
+			runner = "bun"
+			data.FrontendImage = "oven/bun:1-alpine"
+			data.Install = "bun install --frozen-lockfile"
+		} else {
+			data.Install = "npm ci"
+		}
+		break
+	}
+
+	data.Frontend = cfg.Frontend
+	if data.Frontend == "" {
+		data.Frontend = runner + " run build"
+	}
+}
+
+// goMinorVersion trims a go.mod version like 1.25.1 to its image tag, 1.25
+func goMinorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// reportDockerfileData prints what the generated files are tailored to
+func reportDockerfileData(data dockerfileData) {
+	if data.CGO {
+		ui.Step(fmt.Sprintf("Go %s with cgo for %s", data.GoVersion, strings.Join(data.cgoModules, ", ")))
+	} else {
+		ui.Step(fmt.Sprintf("Go %s, static binary", data.GoVersion))
+	}
+	if data.Database != "" {
+		ui.Step("Database: " + data.Database)
+	}
+	if data.Frontend != "" {
+		ui.Step(fmt.Sprintf("Frontend: %s into %s", data.Frontend, data.Assets))
+	}
+	if data.Migrations {
+		ui.Step("Migrations: ./cmd/velocity migrate runs before the server starts")
+	}
+	for _, replace := range data.replaced {
+		ui.Warning(fmt.Sprintf("go.mod replaces %s, which is outside the Docker build context", replace))
+	}
+}
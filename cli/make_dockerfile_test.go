@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectDockerfileData_FullProject(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module shop\n\ngo 1.25.1\n\nrequire github.com/mattn/go-sqlite3 v1.14.22\n\nreplace github.com/velocitykode/velocity => ../velocity\n"), 0644)
+	os.WriteFile("package.json", []byte(`{"scripts":{"build":"vite build"}}`), 0644)
+	os.WriteFile("bun.lock", []byte("{}"), 0644)
+	os.WriteFile(".env.example", []byte("APP_PORT=8080\n"), 0644)
+	os.MkdirAll("cmd/velocity", 0755)
+	os.MkdirAll("public", 0755)
+	os.MkdirAll("resources/views", 0755)
+
+	data, err := projectDockerfileData()
+	if err != nil {
+		t.Fatalf("projectDockerfileData() error = %v", err)
+	}
+
+	if data.GoVersion != "1.25" {
+		t.Errorf("GoVersion = %q, want 1.25", data.GoVersion)
+	}
+	if !data.CGO || data.Database != "sqlite" {
+		t.Errorf("CGO = %v, Database = %q, want cgo for sqlite", data.CGO, data.Database)
+	}
+	if data.FrontendImage != "oven/bun:1-alpine" || data.Frontend != "bun run build" || data.Lockfile != "bun.lock" {
+		t.Errorf("frontend = %q %q %q, want the bun stage", data.FrontendImage, data.Frontend, data.Lockfile)
+	}
+	if data.Assets != defaultAssetsDir {
+		t.Errorf("Assets = %q, want %q", data.Assets, defaultAssetsDir)
+	}
+	if !data.Migrations {
+		t.Error("Migrations should be on when cmd/velocity exists")
+	}
+	if data.Port != "8080" {
+		t.Errorf("Port = %q, want APP_PORT from .env.example", data.Port)
+	}
+	if strings.Join(data.Dirs, ",") != "public,resources/views" {
+		t.Errorf("Dirs = %v", data.Dirs)
+	}
+	if len(data.replaced) != 1 {
+		t.Errorf("replaced = %v, want the local velocity replace", data.replaced)
+	}
+}
+
+func TestProjectDockerfileData_DatabaseFromEnv(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module api\n\ngo 1.24\n"), 0644)
+	os.WriteFile(".env", []byte("DB_CONNECTION=postgres\n"), 0644)
+
+	data, err := projectDockerfileData()
+	if err != nil {
+		t.Fatalf("projectDockerfileData() error = %v", err)
+	}
+	if data.Database != "postgres" || data.CGO || data.Frontend != "" || data.Migrations {
+		t.Errorf("data = %+v, want a static postgres app", data)
+	}
+	if data.Port != defaultAppPort {
+		t.Errorf("Port = %q, want %q", data.Port, defaultAppPort)
+	}
+}
+
+func TestProjectDockerfileData_RequiresGoMod(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	if _, err := projectDockerfileData(); err == nil {
+		t.Error("projectDockerfileData() should fail without go.mod")
+	}
+}
+
+func TestRenderDockerfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    dockerfileData
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "static",
+			data: dockerfileData{Name: "api", GoVersion: "1.25", Port: "4000"},
+			want: []string{
+				"FROM golang:1.25-alpine AS build",
+				"ENV CGO_ENABLED=0",
+				"-X main.version=${VERSION} -X main.date=${BUILD_TIME} -X main.commit=${COMMIT}",
+				"FROM alpine:",
+				"EXPOSE 4000",
+				`CMD ["./server"]`,
+			},
+			notWant: []string{"AS frontend", "/out/migrate", "VOLUME"},
+		},
+		{
+			name: "cgo sqlite with frontend and migrations",
+			data: dockerfileData{
+				Name: "shop", GoVersion: "1.25", CGO: true, Database: "sqlite",
+				Frontend: "npm run build", FrontendImage: "node:22-alpine", Install: "npm ci", Lockfile: "package-lock.json",
+				Assets: "public/build", Dirs: []string{"public"}, Migrations: true, Port: "8080",
+			},
+			want: []string{
+				"FROM node:22-alpine AS frontend",
+				"COPY package.json package-lock.json ./",
+				"RUN npm ci",
+				"RUN npm run build",
+				"FROM golang:1.25 AS build",
+				"ENV CGO_ENABLED=1",
+				"-o /out/migrate ./cmd/velocity",
+				"FROM debian:bookworm-slim",
+				"COPY public ./public",
+				"COPY --from=frontend /src/public/build ./public/build",
+				"VOLUME /app/database",
+				`CMD ["sh", "-c", "./migrate migrate && exec ./server"]`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := renderStub("docker/Dockerfile.stub", tt.data)
+			if err != nil {
+				t.Fatalf("renderStub() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Dockerfile missing %q:\n%s", want, content)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(content), notWant) {
+					t.Errorf("Dockerfile should not contain %q:\n%s", notWant, content)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDockerignore(t *testing.T) {
+	content, err := renderStub("docker/dockerignore.stub", dockerfileData{
+		Name: "shop", Database: "sqlite", Frontend: "npm run build", Assets: "public/build",
+	})
+	if err != nil {
+		t.Fatalf("renderStub() error = %v", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	for _, want := range []string{".env", "!.env.example", "node_modules", "/shop", "public/build", "database/*.db"} {
+		found := false
+		for _, line := range lines {
+			if line == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf(".dockerignore missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestRunMakeDockerfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module api\n\ngo 1.25\n"), 0644)
+	makeDockerfileForce = false
+
+	if err := runMakeDockerfile(nil, nil); err != nil {
+		t.Fatalf("runMakeDockerfile() error = %v", err)
+	}
+	for _, path := range []string{dockerfilePath, dockerignorePath} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was not written", path)
+		}
+	}
+
+	os.WriteFile(dockerfilePath, []byte("custom"), 0644)
+	if err := runMakeDockerfile(nil, nil); err == nil {
+		t.Error("runMakeDockerfile() should refuse to overwrite without --force")
+	}
+	if content, _ := os.ReadFile(dockerfilePath); string(content) != "custom" {
+		t.Error("Dockerfile should be kept without --force")
+	}
+
+	makeDockerfileForce = true
+	defer func() { makeDockerfileForce = false }()
+	if err := runMakeDockerfile(nil, nil); err != nil {
+		t.Fatalf("runMakeDockerfile() --force error = %v", err)
+	}
+	if content, _ := os.ReadFile(dockerfilePath); string(content) == "custom" {
+		t.Error("--force should overwrite the Dockerfile")
+	}
+}
+
+func TestRunMakeDockerfile_UsesProjectStub(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module api\n\ngo 1.25\n"), 0644)
+	customPath := filepath.Join("stubs", "docker", "Dockerfile.stub")
+	os.MkdirAll(filepath.Dir(customPath), 0755)
+	os.WriteFile(customPath, []byte("FROM golang:{{ .GoVersion }}\n"), 0644)
+
+	if err := runMakeDockerfile(nil, nil); err != nil {
+		t.Fatalf("runMakeDockerfile() error = %v", err)
+	}
+	if content, _ := os.ReadFile(dockerfilePath); string(content) != "FROM golang:1.25\n" {
+		t.Errorf("Dockerfile = %q, want the project stub", content)
+	}
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+var (
+	makeMigrationCreate string
+	makeMigrationTable  string
+)
+
+var makeMigrationCmd = &cobra.Command{
+	Use:     "make:migration [name]",
+	Short:   "Create a new migration file",
+	Long:    `Create a new timestamped migration file in the database/migrations directory.`,
+	Example: "  velocity make:migration create_posts_table\n  velocity make:migration add_status_to_posts_table --table=posts",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			ui.Error("migration name is required")
+			ui.Newline()
+			ui.Muted("Usage:")
+			ui.Muted("  velocity make:migration [name]")
+			ui.Newline()
+			ui.Muted("Examples:")
+			ui.Muted("  velocity make:migration create_posts_table")
+			ui.Muted("  velocity make:migration add_status_to_posts_table --table=posts")
+			return fmt.Errorf("") // Return empty error to exit with code 1
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("too many arguments, expected only migration name")
+		}
+		return nil
+	},
+	RunE: runMakeMigration,
+}
+
+func init() {
+	makeMigrationCmd.Flags().StringVar(&makeMigrationCreate, "create", "", "The table to be created")
+	makeMigrationCmd.Flags().StringVar(&makeMigrationTable, "table", "", "The table to migrate")
+	makeMigrationCmd.MarkFlagsMutuallyExclusive("create", "table")
+}
+
+// migrationsDir is where generated migrations are written
+const migrationsDir = "database/migrations"
+
+var (
+	createTablePattern = regexp.MustCompile(`^create_(\w+?)(?:_table)?$`)
+	alterTablePattern  = regexp.MustCompile(`_(?:to|from|in)_(\w+?)(?:_table)?$`)
+
+	// Version: "20240102150405", as written by the migration stub
+	migrationVersionPattern = regexp.MustCompile(`Version:\s*"(\d{14})"`)
+)
+
+// migrationVersionFormat is the layout of a migration's version
+const migrationVersionFormat = "20060102150405"
+
+func runMakeMigration(cmd *cobra.Command, args []string) error {
+	ui.Header("make:migration")
+
+	name := toMigrationName(args[0])
+	if name == "" {
+		ui.Error("Invalid migration name")
+		return fmt.Errorf("invalid migration name: %s", args[0])
+	}
+
+	// Explicit flags take precedence over the table guessed from the name
+	table, create := guessMigrationTable(name)
+	if makeMigrationCreate != "" {
+		table, create = makeMigrationCreate, true
+	} else if makeMigrationTable != "" {
+		table, create = makeMigrationTable, false
+	}
+
+	created := &generatedFiles{}
+	outputPath, err := makeMigration(created, name, table, create)
+	if err != nil {
+		created.rollback()
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("Created: %s", outputPath))
+	return nil
+}
+
+// makeMigration writes a timestamped migration file and returns its path
+func makeMigration(created *generatedFiles, name, table string, create bool) (string, error) {
+	if err := created.mkdirAll(migrationsDir); err != nil {
+		ui.Error(fmt.Sprintf("Failed to create directory: %v", err))
+		return "", err
+	}
+
+	// Check if a migration with this name exists
+	existing, _ := filepath.Glob(filepath.Join(migrationsDir, "*_"+name+".go"))
+	if len(existing) > 0 {
+		ui.Error(fmt.Sprintf("Migration already exists: %s", existing[0]))
+		return "", fmt.Errorf("migration already exists")
+	}
+
+	stamp := nextMigrationTime(migrationsDir, time.Now())
+	outputPath := filepath.Join(migrationsDir, stamp.Format("2006_01_02_150405")+"_"+name+".go")
+
+	content, err := renderMigration(migrationData{
+		Version:     stamp.Format(migrationVersionFormat),
+		Description: strings.ReplaceAll(name, "_", " "),
+		Table:       table,
+		Create:      create,
+	})
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to render migration: %v", err))
+		return "", err
+	}
+
+	if err := created.writeFile(outputPath, content); err != nil {
+		ui.Error(fmt.Sprintf("Failed to write file: %v", err))
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+// nextMigrationTime returns the time to stamp a new migration with: now,
+// or one second after the latest version in dir when that is not earlier.
+// The framework refuses duplicate versions, so two migrations made within
+// the same second must not share one.
+func nextMigrationTime(dir string, now time.Time) time.Time {
+	next := now.Truncate(time.Second)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		m := migrationVersionPattern.FindSubmatch(content)
+		if m == nil {
+			continue
+		}
+		version, err := time.ParseInLocation(migrationVersionFormat, string(m[1]), now.Location())
+		if err != nil {
+			continue
+		}
+		if !next.After(version) {
+			next = version.Add(time.Second)
+		}
+	}
+	return next
+}
+
+// migrationData holds the template data for the migration stub
+type migrationData struct {
+	Version     string
+	Description string
+	Table       string
+	Create      bool
+}
+
+func renderMigration(data migrationData) ([]byte, error) {
+	return renderStub("database/migrations/migration.go.stub", data)
+}
+
+// toMigrationName normalizes a migration name to snake_case
+func toMigrationName(name string) string {
+	words := splitWords(strings.TrimSuffix(name, ".go"))
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// guessMigrationTable infers the table and whether it is being created
+// from names like create_posts_table or add_status_to_posts_table
+func guessMigrationTable(name string) (string, bool) {
+	if m := createTablePattern.FindStringSubmatch(name); m != nil {
+		return m[1], true
+	}
+	if m := alterTablePattern.FindStringSubmatch(name); m != nil {
+		return m[1], false
+	}
+	return "", false
+}
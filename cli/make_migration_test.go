@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToMigrationName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"create_posts_table", "create_posts_table"},
+		{"CreatePostsTable", "create_posts_table"},
+		{"create-posts-table", "create_posts_table"},
+		{"add_status_to_posts.go", "add_status_to_posts"},
+		{"__", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := toMigrationName(tt.input)
+			if got != tt.expected {
+				t.Errorf("toMigrationName(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGuessMigrationTable(t *testing.T) {
+	tests := []struct {
+		input  string
+		table  string
+		create bool
+	}{
+		{"create_posts_table", "posts", true},
+		{"create_blog_posts_table", "blog_posts", true},
+		{"create_posts", "posts", true},
+		{"add_status_to_posts_table", "posts", false},
+		{"remove_status_from_posts_table", "posts", false},
+		{"add_index_in_users", "users", false},
+		{"backfill_slugs", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			table, create := guessMigrationTable(tt.input)
+			if table != tt.table || create != tt.create {
+				t.Errorf("guessMigrationTable(%q) = (%q, %v), want (%q, %v)", tt.input, table, create, tt.table, tt.create)
+			}
+		})
+	}
+}
+
+func TestRenderMigration_ValidGo(t *testing.T) {
+	tests := []migrationData{
+		{Version: "20240101120000", Description: "create posts table", Table: "posts", Create: true},
+		{Version: "20240101120000", Description: "add status to posts table", Table: "posts"},
+		{Version: "20240101120000", Description: "backfill slugs"},
+	}
+
+	for _, data := range tests {
+		t.Run(data.Description, func(t *testing.T) {
+			content, err := renderMigration(data)
+			if err != nil {
+				t.Fatalf("renderMigration() error = %v", err)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), "migration.go", content, 0); err != nil {
+				t.Errorf("Generated migration is not valid Go: %v\n%s", err, content)
+			}
+			if !strings.Contains(string(content), `Version:     "20240101120000"`) {
+				t.Error("Generated migration should contain the version")
+			}
+		})
+	}
+}
+
+func TestRunMakeMigration_CreatesFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	makeMigrationCreate = ""
+	makeMigrationTable = ""
+
+	err := runMakeMigration(nil, []string{"create_posts_table"})
+	if err != nil {
+		t.Fatalf("runMakeMigration() error = %v", err)
+	}
+
+	files, _ := filepath.Glob("database/migrations/*_create_posts_table.go")
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 migration file, got %d", len(files))
+	}
+
+	if !regexp.MustCompile(`^\d{4}_\d{2}_\d{2}_\d{6}_create_posts_table\.go$`).MatchString(filepath.Base(files[0])) {
+		t.Errorf("Unexpected migration filename: %s", files[0])
+	}
+
+	content, _ := os.ReadFile(files[0])
+	if !regexp.MustCompile(`Version:\s+"\d{14}"`).Match(content) {
+		t.Error("Migration should have a 14-digit timestamp version")
+	}
+	if !strings.Contains(string(content), `m.CreateTable("posts"`) {
+		t.Error("Migration should create the posts table")
+	}
+	if !strings.Contains(string(content), `m.DropTable("posts")`) {
+		t.Error("Migration should drop the posts table on rollback")
+	}
+}
+
+func TestRunMakeMigration_TableFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	makeMigrationCreate = ""
+	makeMigrationTable = "articles"
+	defer func() { makeMigrationTable = "" }()
+
+	err := runMakeMigration(nil, []string{"add_status"})
+	if err != nil {
+		t.Fatalf("runMakeMigration() error = %v", err)
+	}
+
+	files, _ := filepath.Glob("database/migrations/*_add_status.go")
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 migration file, got %d", len(files))
+	}
+
+	content, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(content), "Modify the articles table") {
+		t.Error("Migration should modify the table given by --table")
+	}
+	if strings.Contains(string(content), "CreateTable") {
+		t.Error("Migration should not create a table when --table is set")
+	}
+}
+
+func TestRunMakeMigration_UniqueVersions(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	makeMigrationCreate = ""
+	makeMigrationTable = ""
+
+	// Made back to back, both usually land in the same second
+	for _, name := range []string{"create_posts_table", "create_comments_table"} {
+		if err := runMakeMigration(nil, []string{name}); err != nil {
+			t.Fatalf("runMakeMigration(%q) error = %v", name, err)
+		}
+	}
+
+	files, _ := filepath.Glob("database/migrations/*.go")
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 migration files, got %d", len(files))
+	}
+
+	versions := map[string]bool{}
+	prefixes := map[string]bool{}
+	for _, file := range files {
+		content, _ := os.ReadFile(file)
+		m := regexp.MustCompile(`Version:\s+"(\d{14})"`).FindSubmatch(content)
+		if m == nil {
+			t.Fatalf("%s has no version", file)
+		}
+		versions[string(m[1])] = true
+		prefixes[filepath.Base(file)[:17]] = true
+	}
+	if len(versions) != 2 {
+		t.Errorf("Migrations share a version: %v", versions)
+	}
+	if len(prefixes) != 2 {
+		t.Errorf("Migration filenames share a timestamp: %v", prefixes)
+	}
+}
+
+func TestNextMigrationTime(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 2, 15, 4, 5, 500, time.Local)
+
+	if got := nextMigrationTime(dir, now); !got.Equal(now.Truncate(time.Second)) {
+		t.Errorf("nextMigrationTime() with no migrations = %v, want %v", got, now.Truncate(time.Second))
+	}
+
+	os.WriteFile(filepath.Join(dir, "later.go"), []byte(`Version:     "20240102150410",`), 0644)
+	os.WriteFile(filepath.Join(dir, "earlier.go"), []byte(`Version:     "20230101000000",`), 0644)
+
+	want := time.Date(2024, 1, 2, 15, 4, 11, 0, time.Local)
+	if got := nextMigrationTime(dir, now); !got.Equal(want) {
+		t.Errorf("nextMigrationTime() = %v, want %v", got, want)
+	}
+}
+
+func TestRunMakeMigration_AlreadyExists(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	makeMigrationCreate = ""
+	makeMigrationTable = ""
+
+	os.MkdirAll("database/migrations", 0755)
+	os.WriteFile("database/migrations/2024_01_01_000000_create_posts_table.go", []byte("existing"), 0644)
+
+	err := runMakeMigration(nil, []string{"create_posts_table"})
+	if err == nil {
+		t.Error("runMakeMigration() should error when migration exists")
+	}
+}
+
+func TestRunMakeMigration_NoArgs(t *testing.T) {
+	err := makeMigrationCmd.Args(makeMigrationCmd, []string{})
+	if err == nil {
+		t.Error("Args validation should error with no args")
+	}
+}
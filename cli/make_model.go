@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+var (
+	makeModelMigration  bool
+	makeModelController bool
+	makeModelResource   bool
+	makeModelFactory    bool
+	makeModelForce      bool
+)
+
+var makeModelCmd = &cobra.Command{
+	Use:     "make:model [name]",
+	Short:   "Create a new model",
+	Long:    `Create a new ORM model in the app/models directory, optionally with a migration, controller and factory.`,
+	Example: "  velocity make:model Post\n  velocity make:model Post -m -c -r\n  velocity make:model Post --migration --factory",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			ui.Error("model name is required")
+			ui.Newline()
+			ui.Muted("Usage:")
+			ui.Muted("  velocity make:model [name]")
+			ui.Newline()
+			ui.Muted("Examples:")
+			ui.Muted("  velocity make:model Post")
+			ui.Muted("  velocity make:model Post -m -c -r")
+			return fmt.Errorf("") // Return empty error to exit with code 1
+		}
+		if len(args) > 1 {
+			return fmt.Errorf("too many arguments, expected only model name")
+		}
+		return nil
+	},
+	RunE: runMakeModel,
+}
+
+func init() {
+	makeModelCmd.Flags().BoolVarP(&makeModelMigration, "migration", "m", false, "Create a migration for the model")
+	makeModelCmd.Flags().BoolVarP(&makeModelController, "controller", "c", false, "Create a controller for the model")
+	makeModelCmd.Flags().BoolVarP(&makeModelResource, "resource", "r", false, "Create a resource controller for the model")
+	makeModelCmd.Flags().BoolVarP(&makeModelFactory, "factory", "f", false, "Create a factory for the model")
+	makeModelCmd.Flags().BoolVar(&makeModelForce, "force", false, "Overwrite the model, controller and factory if they exist")
+}
+
+// modelsDir is where generated models are written
+const modelsDir = "app/models"
+
+// factoriesDir is where generated factories are written
+const factoriesDir = "database/factories"
+
+// modelData holds the template data for the model and factory stubs
+type modelData struct {
+	ModelName string
+	Table     string
+}
+
+func runMakeModel(cmd *cobra.Command, args []string) error {
+	ui.Header("make:model")
+
+	if strings.Contains(args[0], "/") {
+		ui.Error("Model names cannot be nested")
+		return fmt.Errorf("invalid model name: %s", args[0])
+	}
+
+	modelName := toPascalCase(args[0])
+	if modelName == "" {
+		ui.Error("Invalid model name")
+		return fmt.Errorf("invalid model name: %s", args[0])
+	}
+
+	data := modelData{
+		ModelName: modelName,
+		Table:     pluralize(toMigrationName(modelName)),
+	}
+
+	// Each step registers the files it writes so a failure part way
+	// through leaves the project as it was
+	created := &generatedFiles{}
+	var paths []string
+
+	steps := []func() (string, error){
+		func() (string, error) { return makeModel(created, data, makeModelForce) },
+	}
+	if makeModelMigration {
+		steps = append(steps, func() (string, error) {
+			return makeMigration(created, "create_"+data.Table+"_table", data.Table, true)
+		})
+	}
+	if makeModelController || makeModelResource {
+		steps = append(steps, func() (string, error) {
+			return makeController(created, modelName, makeModelResource, false, makeModelForce)
+		})
+	}
+	if makeModelFactory {
+		steps = append(steps, func() (string, error) { return makeFactory(created, data, makeModelForce) })
+	}
+
+	for _, step := range steps {
+		path, err := step()
+		if err != nil {
+			for _, removed := range created.rollback() {
+				ui.Warning(fmt.Sprintf("Rolled back: %s", removed))
+			}
+			return err
+		}
+		paths = append(paths, path)
+	}
+
+	for _, path := range paths {
+		ui.Success(fmt.Sprintf("Created: %s", path))
+	}
+	return nil
+}
+
+// makeModel writes the model struct and returns its path
+func makeModel(created *generatedFiles, data modelData, force bool) (string, error) {
+	outputPath := filepath.Join(modelsDir, toSnakeCase(data.ModelName)+".go")
+	return writeStub(created, "app/models/model.go.stub", outputPath, "model", data, force)
+}
+
+// makeFactory writes the model factory and returns its path
+func makeFactory(created *generatedFiles, data modelData, force bool) (string, error) {
+	outputPath := filepath.Join(factoriesDir, toSnakeCase(data.ModelName)+"_factory.go")
+	return writeStub(created, "database/factories/factory.go.stub", outputPath, "factory", data, force)
+}
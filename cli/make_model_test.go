@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func resetMakeModelFlags() {
+	makeModelMigration = false
+	makeModelController = false
+	makeModelResource = false
+	makeModelFactory = false
+	makeModelForce = false
+}
+
+func TestRunMakeModel_CreatesFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	resetMakeModelFlags()
+
+	err := runMakeModel(nil, []string{"blog_post"})
+	if err != nil {
+		t.Fatalf("runMakeModel() error = %v", err)
+	}
+
+	content, err := os.ReadFile("app/models/blog_post.go")
+	if err != nil {
+		t.Fatal("Model file not created at app/models/blog_post.go")
+	}
+	if !strings.Contains(string(content), "orm.Model[BlogPost]") {
+		t.Error("Model should embed orm.Model[BlogPost]")
+	}
+	if !strings.Contains(string(content), `return "blog_posts"`) {
+		t.Error("Model should use the pluralized table name")
+	}
+}
+
+func TestRunMakeModel_WithMigrationControllerFactory(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	resetMakeModelFlags()
+	makeModelMigration = true
+	makeModelResource = true
+	makeModelFactory = true
+	defer resetMakeModelFlags()
+
+	err := runMakeModel(nil, []string{"Category"})
+	if err != nil {
+		t.Fatalf("runMakeModel() error = %v", err)
+	}
+
+	migrations, _ := filepath.Glob("database/migrations/*_create_categories_table.go")
+	if len(migrations) != 1 {
+		t.Error("Migration should be created for the model")
+	}
+
+	controller, err := os.ReadFile("app/http/controllers/category_controller.go")
+	if err != nil {
+		t.Fatal("Controller should be created for the model")
+	}
+	if !strings.Contains(string(controller), "func CategoryIndex(") {
+		t.Error("--resource should generate a resource controller")
+	}
+
+	factory, err := os.ReadFile("database/factories/category_factory.go")
+	if err != nil {
+		t.Fatal("Factory should be created for the model")
+	}
+	if !strings.Contains(string(factory), `NewFactory("categories"`) {
+		t.Error("Factory should target the model table")
+	}
+}
+
+func TestRunMakeModel_RollsBackOnFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	// An existing controller makes the controller step fail
+	os.MkdirAll("app/http/controllers", 0755)
+	os.WriteFile("app/http/controllers/post_controller.go", []byte("existing"), 0644)
+
+	resetMakeModelFlags()
+	makeModelMigration = true
+	makeModelController = true
+	defer resetMakeModelFlags()
+
+	err := runMakeModel(nil, []string{"Post"})
+	if err == nil {
+		t.Fatal("runMakeModel() should error when a step fails")
+	}
+
+	if _, err := os.Stat("app/models"); !os.IsNotExist(err) {
+		t.Error("Model and its directory should be removed after failure")
+	}
+	if _, err := os.Stat("database"); !os.IsNotExist(err) {
+		t.Error("Migration and its directory should be removed after failure")
+	}
+
+	content, _ := os.ReadFile("app/http/controllers/post_controller.go")
+	if string(content) != "existing" {
+		t.Error("Existing controller should be left untouched")
+	}
+}
+
+func TestRunMakeModel_AlreadyExists(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	resetMakeModelFlags()
+
+	os.MkdirAll("app/models", 0755)
+	os.WriteFile("app/models/post.go", []byte("existing"), 0644)
+
+	err := runMakeModel(nil, []string{"Post"})
+	if err == nil {
+		t.Error("runMakeModel() should error when model exists")
+	}
+}
+
+func TestRunMakeModel_NestedName(t *testing.T) {
+	resetMakeModelFlags()
+
+	err := runMakeModel(nil, []string{"Admin/Post"})
+	if err == nil {
+		t.Error("runMakeModel() should reject nested model names")
+	}
+}
+
+func TestRunMakeModel_NoArgs(t *testing.T) {
+	err := makeModelCmd.Args(makeModelCmd, []string{})
+	if err == nil {
+		t.Error("Args validation should error with no args")
+	}
+}
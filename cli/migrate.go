@@ -1,8 +1,10 @@
 package cli
 
 import (
+	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/velocitykode/velocity-cli/internal/ui"
@@ -24,6 +26,41 @@ var migrateFreshCmd = &cobra.Command{
 	RunE:  runMigrateFresh,
 }
 
+var migrateRollbackStep int
+
+var migrateRollbackCmd = &cobra.Command{
+	Use:     "migrate:rollback",
+	Short:   "Rollback the last batch of migrations",
+	Long:    `Roll back the last batch of migrations, or the last N batches with --step.`,
+	Example: "  velocity migrate:rollback\n  velocity migrate:rollback --step 2",
+	RunE:    runMigrateRollback,
+}
+
+var migrateResetCmd = &cobra.Command{
+	Use:   "migrate:reset",
+	Short: "Rollback all database migrations",
+	Long:  `Roll back every applied migration, newest batch first.`,
+	RunE:  runMigrateReset,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "migrate:status",
+	Short: "Show the status of each migration",
+	Long:  `List every registered migration with its applied state, batch and applied-at time.`,
+	RunE:  runMigrateStatus,
+}
+
+func init() {
+	migrateRollbackCmd.Flags().IntVar(&migrateRollbackStep, "step", 1, "Number of batches to roll back")
+}
+
+// appliedMigration is a row of the migrations table
+type appliedMigration struct {
+	Version    string
+	Batch      int
+	ExecutedAt string
+}
+
 func runMigrate(cmd *cobra.Command, args []string) error {
 	ui.Header("migrate")
 
@@ -140,3 +177,190 @@ func getPendingMigrations(migrator *migrate.Migrator, all []migrate.Migration) (
 
 	return pending, nil
 }
+
+func runMigrateRollback(cmd *cobra.Command, args []string) error {
+	ui.Header("migrate:rollback")
+
+	if migrateRollbackStep < 1 {
+		ui.Error("--step must be at least 1")
+		return fmt.Errorf("invalid step: %d", migrateRollbackStep)
+	}
+
+	return rollbackMigrations(migrateRollbackStep, false)
+}
+
+func runMigrateReset(cmd *cobra.Command, args []string) error {
+	ui.Header("migrate:reset")
+
+	return rollbackMigrations(0, true)
+}
+
+// rollbackMigrations rolls back the last steps batches, or every batch when all is set
+func rollbackMigrations(steps int, all bool) error {
+	// Initialize database from environment
+	if err := orm.InitFromEnv(); err != nil {
+		ui.Error(fmt.Sprintf("Database connection failed: %v", err))
+		return err
+	}
+
+	// Create migrator
+	driverName := os.Getenv("DB_CONNECTION")
+	migrator := migrate.NewMigrator(orm.DB(), driverName)
+
+	applied, err := getAppliedMigrations()
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to get applied migrations: %v", err))
+		return err
+	}
+
+	if len(applied) == 0 {
+		ui.Info("Nothing to rollback")
+		return nil
+	}
+
+	// Applied migrations are ordered newest batch first
+	lastBatch := applied[0].Batch
+	if all {
+		steps = lastBatch
+	}
+
+	var targets []appliedMigration
+	for _, m := range applied {
+		if m.Batch > lastBatch-steps {
+			targets = append(targets, m)
+		}
+	}
+
+	ui.Info("Rolling back migrations")
+
+	if err := migrator.Down(steps); err != nil {
+		ui.Error(fmt.Sprintf("Rollback failed: %v", err))
+		return err
+	}
+
+	for _, m := range targets {
+		ui.Success(migrationName(m.Version))
+	}
+
+	ui.Newline()
+	ui.Success("Done")
+	return nil
+}
+
+func runMigrateStatus(cmd *cobra.Command, args []string) error {
+	ui.Header("migrate:status")
+
+	// Initialize database from environment
+	if err := orm.InitFromEnv(); err != nil {
+		ui.Error(fmt.Sprintf("Database connection failed: %v", err))
+		return err
+	}
+
+	// Get all registered migrations
+	migrations := migrate.All()
+	if len(migrations) == 0 {
+		ui.Warning("No migrations found")
+		return nil
+	}
+
+	// Create migrator
+	driverName := os.Getenv("DB_CONNECTION")
+	migrator := migrate.NewMigrator(orm.DB(), driverName)
+
+	pending, err := getPendingMigrations(migrator, migrations)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to get pending migrations: %v", err))
+		return err
+	}
+
+	applied, err := getAppliedMigrations()
+	if err != nil {
+		ui.Error(fmt.Sprintf("Failed to get applied migrations: %v", err))
+		return err
+	}
+
+	ui.Table([]string{"Migration", "State", "Batch", "Applied at"}, migrationStatusRows(migrations, pending, applied))
+
+	ui.Newline()
+	ui.Info(fmt.Sprintf("%d applied, %d pending", len(migrations)-len(pending), len(pending)))
+	return nil
+}
+
+// migrationStatusRows builds one status table row per registered migration
+func migrationStatusRows(all, pending []migrate.Migration, applied []appliedMigration) [][]string {
+	pendingVersions := make(map[string]bool)
+	for _, m := range pending {
+		pendingVersions[m.Version] = true
+	}
+
+	appliedByVersion := make(map[string]appliedMigration)
+	for _, m := range applied {
+		appliedByVersion[m.Version] = m
+	}
+
+	rows := make([][]string, 0, len(all))
+	for _, m := range all {
+		name := fmt.Sprintf("%s_%s", m.Version, m.Description)
+		if pendingVersions[m.Version] {
+			rows = append(rows, []string{name, "Pending", "", ""})
+			continue
+		}
+
+		record := appliedByVersion[m.Version]
+		rows = append(rows, []string{name, "Applied", fmt.Sprintf("%d", record.Batch), record.ExecutedAt})
+	}
+
+	return rows
+}
+
+// getAppliedMigrations returns applied migrations ordered newest batch first
+func getAppliedMigrations() ([]appliedMigration, error) {
+	db := orm.DB()
+	rows, err := db.Query("SELECT version, batch, executed_at FROM migrations ORDER BY batch DESC, version DESC")
+	if err != nil {
+		// The table is created by the first migration run, so until then
+		// nothing has been applied
+		if isMissingTable(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []appliedMigration
+	for rows.Next() {
+		var m appliedMigration
+		var executedAt sql.NullString
+		if err := rows.Scan(&m.Version, &m.Batch, &executedAt); err != nil {
+			return nil, err
+		}
+		m.ExecutedAt = executedAt.String
+		applied = append(applied, m)
+	}
+
+	return applied, rows.Err()
+}
+
+// isMissingTable reports whether err is a query failing on a table that
+// does not exist, as each supported driver words it
+func isMissingTable(err error) bool {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "no such table"): // SQLite
+		return true
+	case strings.Contains(message, "relation") && strings.Contains(message, "does not exist"): // PostgreSQL
+		return true
+	case strings.Contains(message, "Error 1146"): // MySQL
+		return true
+	}
+	return false
+}
+
+// migrationName returns the display name of a migration version
+func migrationName(version string) string {
+	m, err := migrate.Find(version)
+	if err != nil {
+		return version
+	}
+	return fmt.Sprintf("%s_%s", m.Version, m.Description)
+}
@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/velocitykode/velocity/pkg/orm/migrate"
 )
 
 func TestRunMigrate_FailsWithoutDatabase(t *testing.T) {
@@ -21,5 +25,102 @@ func TestRunMigrateFresh_FailsWithoutDatabase(t *testing.T) {
 	}
 }
 
+func TestRunMigrateRollback_FailsWithoutDatabase(t *testing.T) {
+	migrateRollbackStep = 1
+	err := runMigrateRollback(nil, nil)
+	if err == nil {
+		t.Error("runMigrateRollback() should error when database not configured")
+	}
+}
+
+func TestRunMigrateRollback_InvalidStep(t *testing.T) {
+	migrateRollbackStep = 0
+	defer func() { migrateRollbackStep = 1 }()
+
+	err := runMigrateRollback(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid step") {
+		t.Errorf("runMigrateRollback() error = %v, want invalid step", err)
+	}
+}
+
+func TestRunMigrateReset_FailsWithoutDatabase(t *testing.T) {
+	err := runMigrateReset(nil, nil)
+	if err == nil {
+		t.Error("runMigrateReset() should error when database not configured")
+	}
+}
+
+func TestRunMigrateStatus_FailsWithoutDatabase(t *testing.T) {
+	err := runMigrateStatus(nil, nil)
+	if err == nil {
+		t.Error("runMigrateStatus() should error when database not configured")
+	}
+}
+
+func TestMigrateRollbackCmd_StepDefault(t *testing.T) {
+	flag := migrateRollbackCmd.Flags().Lookup("step")
+	if flag == nil {
+		t.Fatal("Flag \"step\" not found")
+	}
+	if flag.DefValue != "1" {
+		t.Errorf("Flag \"step\" default = %q, want %q", flag.DefValue, "1")
+	}
+}
+
+func TestMigrationStatusRows(t *testing.T) {
+	all := []migrate.Migration{
+		{Version: "20240101000000", Description: "create users table"},
+		{Version: "20240102000000", Description: "create posts table"},
+	}
+	pending := []migrate.Migration{all[1]}
+	applied := []appliedMigration{
+		{Version: "20240101000000", Batch: 3, ExecutedAt: "2024-01-01 10:00:00"},
+	}
+
+	rows := migrationStatusRows(all, pending, applied)
+	if len(rows) != 2 {
+		t.Fatalf("migrationStatusRows() returned %d rows, want 2", len(rows))
+	}
+
+	want := [][]string{
+		{"20240101000000_create users table", "Applied", "3", "2024-01-01 10:00:00"},
+		{"20240102000000_create posts table", "Pending", "", ""},
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("rows[%d][%d] = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func TestMigrationName_UnknownVersion(t *testing.T) {
+	if got := migrationName("19990101000000"); got != "19990101000000" {
+		t.Errorf("migrationName() = %q, want version for unregistered migration", got)
+	}
+}
+
 // Note: Full integration tests for migrate require a real database
 // Those should be in a separate integration test file with build tag
+
+func TestIsMissingTable(t *testing.T) {
+	tests := []struct {
+		err  string
+		want bool
+	}{
+		{"no such table: migrations", true},
+		{`pq: relation "migrations" does not exist`, true},
+		{"Error 1146 (42S02): Table 'app.migrations' doesn't exist", true},
+		{"dial tcp 127.0.0.1:5432: connect: connection refused", false},
+		{`pq: column "batch" does not exist`, false},
+		{`pq: permission denied for table migrations`, false},
+		{"database is locked", false},
+	}
+
+	for _, tt := range tests {
+		if got := isMissingTable(errors.New(tt.err)); got != tt.want {
+			t.Errorf("isMissingTable(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+var (
+	routeListMethod string
+	routeListPath   string
+	routeListJSON   bool
+)
+
+var routeListCmd = &cobra.Command{
+	Use:   "route:list",
+	Short: "List all registered routes",
+	Long: `List every route registered through router.Register in the routes directory,
+with its method, path, name, handler and middleware chain. Router calls no
+route can be read from are reported rather than left out silently, as are
+Resource registrations, which the framework does not turn into routes yet.`,
+	Example: "  velocity route:list\n  velocity route:list --method GET --path /api\n  velocity route:list --json",
+	RunE:    runRouteList,
+}
+
+func init() {
+	routeListCmd.Flags().StringVar(&routeListMethod, "method", "", "Only show routes for the given HTTP method")
+	routeListCmd.Flags().StringVar(&routeListPath, "path", "", "Only show routes whose path contains the given value")
+	routeListCmd.Flags().BoolVar(&routeListJSON, "json", false, "Output the routes as JSON")
+}
+
+// routesDir is where route registration files live
+const routesDir = "routes"
+
+// routeMethods maps router methods to the HTTP method they register
+var routeMethods = map[string]string{
+	"Get":     "GET",
+	"Post":    "POST",
+	"Put":     "PUT",
+	"Delete":  "DELETE",
+	"Patch":   "PATCH",
+	"Options": "OPTIONS",
+	"Head":    "HEAD",
+}
+
+// routeInfo describes a single registered route
+type routeInfo struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Name       string   `json:"name"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+}
+
+func runRouteList(cmd *cobra.Command, args []string) error {
+	routes, unresolved, err := loadRoutes(routesDir, "main.go")
+	if err != nil {
+		if !routeListJSON {
+			ui.Header("route:list")
+		}
+		ui.Error(fmt.Sprintf("Failed to load routes: %v", err))
+		return err
+	}
+
+	routes = filterRoutes(routes, routeListMethod, routeListPath)
+
+	if routeListJSON {
+		out, err := json.MarshalIndent(routes, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		for _, call := range unresolved {
+			fmt.Fprintf(os.Stderr, "Not listed: %s\n", call)
+		}
+		return nil
+	}
+
+	ui.Header("route:list")
+
+	for _, call := range unresolved {
+		ui.Warning(fmt.Sprintf("Not listed: %s", call))
+	}
+
+	if len(routes) == 0 {
+		ui.Warning("No routes found")
+		return nil
+	}
+
+	rows := make([][]string, 0, len(routes))
+	for _, r := range routes {
+		rows = append(rows, []string{r.Method, r.Path, r.Name, r.Handler, strings.Join(r.Middleware, ", ")})
+	}
+	ui.Table([]string{"Method", "Path", "Name", "Handler", "Middleware"}, rows)
+
+	ui.Newline()
+	ui.Muted(fmt.Sprintf("Showing %d routes", len(routes)))
+	return nil
+}
+
+// filterRoutes keeps routes matching method (case-insensitive) and whose
+// path contains path. Empty filters match everything.
+func filterRoutes(routes []routeInfo, method, path string) []routeInfo {
+	filtered := make([]routeInfo, 0, len(routes))
+	for _, r := range routes {
+		if method != "" && !strings.EqualFold(r.Method, method) {
+			continue
+		}
+		if path != "" && !strings.Contains(r.Path, path) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
+
+// loadRoutes reads the route files in dir and returns the routes they
+// register, sorted by path then method, and the position, source and
+// reason of router calls it did not list. Middleware applied to the
+// global router in mainFile is prepended to every route's chain.
+//
+// The framework keeps its registrations private, so routes are read from
+// the source instead of by running the application.
+func loadRoutes(dir, mainFile string) ([]routeInfo, []string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	global := globalMiddleware(mainFile)
+
+	fset := token.NewFileSet()
+	var routes []routeInfo
+	var unresolved []string
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		routerPkg := routerImportName(file)
+		if routerPkg == "" {
+			continue
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || !isPackageCall(call, routerPkg, "Register", "RegisterWithPrefix") {
+				return true
+			}
+
+			fn, ok := call.Args[len(call.Args)-1].(*ast.FuncLit)
+			if !ok || len(fn.Type.Params.List) == 0 || len(fn.Type.Params.List[0].Names) == 0 {
+				return false
+			}
+
+			root := routeGroup{middleware: global}
+			if len(call.Args) == 2 {
+				root.prefix = stringValue(call.Args[0])
+			}
+
+			s := &routeScanner{
+				fset:   fset,
+				groups: map[string]routeGroup{fn.Type.Params.List[0].Names[0].Name: root},
+				types:  map[string]string{},
+			}
+			s.scan(fn.Body.List)
+			routes = append(routes, s.routes...)
+			unresolved = append(unresolved, s.unresolved...)
+			return false
+		})
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, unresolved, nil
+}
+
+// routeGroup is the prefix and middleware a router variable applies
+type routeGroup struct {
+	prefix     string
+	middleware []string
+}
+
+// routeScanner follows router variables through a registration function
+type routeScanner struct {
+	fset       *token.FileSet
+	groups     map[string]routeGroup // Router variables in scope
+	types      map[string]string     // Controller variables and their types
+	routes     []routeInfo
+	unresolved []string // Router calls not listed, with the reason
+}
+
+// scan walks statements in order, so group middleware only applies to
+// routes registered after it, as it does at runtime
+func (s *routeScanner) scan(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		switch st := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(st.Lhs) != 1 || len(st.Rhs) != 1 {
+				continue
+			}
+			ident, ok := st.Lhs[0].(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if group, ok := s.resolveGroup(st.Rhs[0]); ok {
+				s.groups[ident.Name] = group
+			} else if typeName := compositeType(st.Rhs[0]); typeName != "" {
+				s.types[ident.Name] = typeName
+			}
+		case *ast.DeclStmt:
+			gen, ok := st.Decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok || len(vs.Names) != len(vs.Values) {
+					continue
+				}
+				for i, name := range vs.Names {
+					if typeName := compositeType(vs.Values[i]); typeName != "" {
+						s.types[name.Name] = typeName
+					}
+				}
+			}
+		case *ast.ExprStmt:
+			s.scanCall(st.X)
+		case *ast.BlockStmt:
+			s.scan(st.List)
+		}
+	}
+}
+
+// scanCall records a route from a chain like
+// api.Get("/posts", h).Name("posts.index").Use(auth), adds middleware to
+// a group for calls like api.Use(auth), or replaces a group's prefix for
+// calls like api.Prefix("/v2")
+func (s *routeScanner) scanCall(expr ast.Expr) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	if sel.Sel.Name == "Use" {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if group, ok := s.groups[ident.Name]; ok {
+				group.middleware = appendMiddleware(append([]string(nil), group.middleware...), call.Args)
+				s.groups[ident.Name] = group
+				return
+			}
+		}
+	}
+
+	// Prefix replaces the router's prefix for the routes registered on it
+	// afterwards
+	if sel.Sel.Name == "Prefix" && len(call.Args) == 1 {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if group, ok := s.groups[ident.Name]; ok {
+				group.prefix = stringValue(call.Args[0])
+				s.groups[ident.Name] = group
+				return
+			}
+		}
+	}
+
+	// Unwind .Name(), .Use(), .Only() and .Except() calls back to the
+	// route registration
+	outer := call
+	var name string
+	var routeMiddleware [][]ast.Expr
+	for {
+		// The framework accepts Resource but registers no routes for it
+		if sel.Sel.Name == "Resource" {
+			s.unresolve(sel, outer, "Resource registers no routes in this framework version")
+			return
+		}
+
+		if method, ok := routeMethods[sel.Sel.Name]; ok && len(call.Args) == 2 {
+			group, ok := s.resolveGroup(sel.X)
+			if !ok {
+				return
+			}
+			middleware := append([]string{}, group.middleware...)
+			for _, args := range routeMiddleware {
+				middleware = appendMiddleware(middleware, args)
+			}
+			s.routes = append(s.routes, routeInfo{
+				Method:     method,
+				Path:       group.prefix + stringValue(call.Args[0]),
+				Name:       name,
+				Handler:    s.handlerName(call.Args[1]),
+				Middleware: middleware,
+			})
+			return
+		}
+
+		switch sel.Sel.Name {
+		case "Name":
+			// The outermost call runs last, so its name wins
+			if name == "" && len(call.Args) == 1 {
+				name = stringValue(call.Args[0])
+			}
+		case "Use":
+			routeMiddleware = append([][]ast.Expr{call.Args}, routeMiddleware...)
+		case "Only", "Except":
+			// Options of a Resource registration
+		default:
+			s.unresolve(sel, outer, "the route could not be read from the source")
+			return
+		}
+
+		if call, ok = sel.X.(*ast.CallExpr); !ok {
+			return
+		}
+		if sel, ok = call.Fun.(*ast.SelectorExpr); !ok {
+			return
+		}
+	}
+}
+
+// unresolve notes call and why it is not listed when sel is a method
+// called on a router the scanner follows, so a route it registers is not
+// silently missing
+func (s *routeScanner) unresolve(sel *ast.SelectorExpr, call *ast.CallExpr, reason string) {
+	if _, ok := s.resolveGroup(sel.X); !ok {
+		return
+	}
+	switch sel.Sel.Name {
+	case "Group", "Static":
+		return
+	}
+	s.unresolved = append(s.unresolved, fmt.Sprintf("%s: %s: %s", s.fset.Position(call.Pos()), types.ExprString(call), reason))
+}
+
+// resolveGroup returns the group a router expression refers to, following
+// variables, Group() and Use() calls. Use() adds to the router it is called
+// on, so a router variable it is called on is updated as well.
+func (s *routeScanner) resolveGroup(expr ast.Expr) (routeGroup, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		group, ok := s.groups[e.Name]
+		return group, ok
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return routeGroup{}, false
+		}
+		parent, ok := s.resolveGroup(sel.X)
+		if !ok {
+			return routeGroup{}, false
+		}
+		switch sel.Sel.Name {
+		case "Group":
+			if len(e.Args) != 1 {
+				return routeGroup{}, false
+			}
+			return routeGroup{
+				prefix:     parent.prefix + stringValue(e.Args[0]),
+				middleware: append([]string(nil), parent.middleware...),
+			}, true
+		case "Use":
+			parent.middleware = appendMiddleware(append([]string(nil), parent.middleware...), e.Args)
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				s.groups[ident.Name] = parent
+			}
+			return parent, true
+		}
+	}
+	return routeGroup{}, false
+}
+
+// handlerName describes a handler expression, resolving controller
+// variables to their type, e.g. controllers.HomeController.Index
+func (s *routeScanner) handlerName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.FuncLit:
+		return "Closure"
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok {
+			if typeName, ok := s.types[ident.Name]; ok {
+				return typeName + "." + e.Sel.Name
+			}
+		}
+	}
+	return types.ExprString(expr)
+}
+
+// appendMiddleware appends the printed form of each middleware expression
+func appendMiddleware(middleware []string, args []ast.Expr) []string {
+	for _, arg := range args {
+		middleware = append(middleware, types.ExprString(arg))
+	}
+	return middleware
+}
+
+// globalMiddleware returns the middleware passed to Use on the global
+// router in the application's main file
+func globalMiddleware(mainFile string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), mainFile, nil, 0)
+	if err != nil {
+		return nil
+	}
+
+	routerPkg := routerImportName(file)
+	if routerPkg == "" {
+		return nil
+	}
+
+	var middleware []string
+	global := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) == 1 && len(node.Rhs) == 1 {
+				if call, ok := node.Rhs[0].(*ast.CallExpr); ok && isPackageCall(call, routerPkg, "Get") {
+					if ident, ok := node.Lhs[0].(*ast.Ident); ok {
+						global[ident.Name] = true
+					}
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Use" {
+				return true
+			}
+			ident, isIdent := sel.X.(*ast.Ident)
+			call, isCall := sel.X.(*ast.CallExpr)
+			if (isIdent && global[ident.Name]) || (isCall && isPackageCall(call, routerPkg, "Get")) {
+				middleware = appendMiddleware(middleware, node.Args)
+			}
+		}
+		return true
+	})
+	return middleware
+}
+
+// routerImportName returns the name the file imports the router package
+// under, or "" when it does not import it
+func routerImportName(file *ast.File) string {
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || !strings.HasSuffix(path, "/pkg/router") {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return "router"
+	}
+	return ""
+}
+
+// isPackageCall reports whether call is pkg.<one of names>(...)
+func isPackageCall(call *ast.CallExpr, pkg string, names ...string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || ident.Name != pkg {
+		return false
+	}
+	for _, name := range names {
+		if sel.Sel.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// compositeType returns the type of a T{} or &T{} expression
+func compositeType(expr ast.Expr) string {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	if lit, ok := expr.(*ast.CompositeLit); ok && lit.Type != nil {
+		return types.ExprString(lit.Type)
+	}
+	return ""
+}
+
+// stringValue returns the value of a string literal, or the source of any
+// other expression
+func stringValue(expr ast.Expr) string {
+	if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		if value, err := strconv.Unquote(lit.Value); err == nil {
+			return value
+		}
+	}
+	return types.ExprString(expr)
+}
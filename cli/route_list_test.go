@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testRoutesFile = `package routes
+
+import (
+	"example.com/app/app/http/controllers"
+	"example.com/app/app/http/middleware"
+
+	"github.com/velocitykode/velocity/pkg/router"
+)
+
+func init() {
+	router.Register(func(r router.Router) {
+		home := controllers.HomeController{}
+		r.Get("/", home.Index).Name("home")
+
+		api := r.Group("/api/v1")
+		api.Get("/health", healthCheck).Name("api.health")
+
+		protected := api.Group("")
+		protected.Use(middleware.APIAuthMiddleware)
+		protected.Post("/posts", createPost).Name("api.posts.create").Use(middleware.Throttle)
+		protected.Delete("/posts/{id}", deletePost)
+
+		api.Get("/status", func(ctx *router.Context) error { return nil })
+	})
+
+	router.RegisterWithPrefix("/admin", func(r router.Router) {
+		r.Put("/settings", updateSettings).Name("admin.settings")
+	})
+}
+`
+
+const testMainFile = `package main
+
+import "github.com/velocitykode/velocity/pkg/router"
+
+func main() {
+	r := router.Get()
+	r.Use(middleware.RecoveryMiddleware, middleware.LoggingMiddleware)
+	router.LoadRoutes()
+}
+`
+
+func writeTestRoutes(t *testing.T) {
+	t.Helper()
+	os.MkdirAll("routes", 0755)
+	os.WriteFile(filepath.Join("routes", "web.go"), []byte(testRoutesFile), 0644)
+	os.WriteFile("main.go", []byte(testMainFile), 0644)
+}
+
+func TestLoadRoutes(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	writeTestRoutes(t)
+
+	routes, _, err := loadRoutes("routes", "main.go")
+	if err != nil {
+		t.Fatalf("loadRoutes() error = %v", err)
+	}
+
+	global := []string{"middleware.RecoveryMiddleware", "middleware.LoggingMiddleware"}
+	auth := append(append([]string{}, global...), "middleware.APIAuthMiddleware")
+	expected := []routeInfo{
+		{"GET", "/", "home", "controllers.HomeController.Index", global},
+		{"PUT", "/admin/settings", "admin.settings", "updateSettings", global},
+		{"GET", "/api/v1/health", "api.health", "healthCheck", global},
+		{"POST", "/api/v1/posts", "api.posts.create", "createPost", append(append([]string{}, auth...), "middleware.Throttle")},
+		{"DELETE", "/api/v1/posts/{id}", "", "deletePost", auth},
+		{"GET", "/api/v1/status", "", "Closure", global},
+	}
+
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("loadRoutes() =\n%+v\nwant\n%+v", routes, expected)
+	}
+}
+
+func TestLoadRoutes_NoRoutesDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	routes, _, err := loadRoutes("routes", "main.go")
+	if err != nil {
+		t.Fatalf("loadRoutes() error = %v", err)
+	}
+	if len(routes) != 0 {
+		t.Errorf("Expected no routes, got %d", len(routes))
+	}
+}
+
+func TestFilterRoutes(t *testing.T) {
+	routes := []routeInfo{
+		{Method: "GET", Path: "/"},
+		{Method: "GET", Path: "/api/v1/posts"},
+		{Method: "POST", Path: "/api/v1/posts"},
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{"", "", 3},
+		{"get", "", 2},
+		{"", "/api", 2},
+		{"POST", "/api", 1},
+		{"DELETE", "", 0},
+	}
+
+	for _, tt := range tests {
+		got := filterRoutes(routes, tt.method, tt.path)
+		if len(got) != tt.want {
+			t.Errorf("filterRoutes(%q, %q) returned %d routes, want %d", tt.method, tt.path, len(got), tt.want)
+		}
+	}
+}
+
+func TestRunRouteList_JSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	writeTestRoutes(t)
+
+	routeListJSON = true
+	routeListMethod = "delete"
+	defer func() {
+		routeListJSON = false
+		routeListMethod = ""
+	}()
+
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+	err := runRouteList(nil, nil)
+	w.Close()
+	os.Stdout = stdout
+
+	if err != nil {
+		t.Fatalf("runRouteList() error = %v", err)
+	}
+
+	out, _ := io.ReadAll(r)
+	var routes []routeInfo
+	if err := json.Unmarshal(out, &routes); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, out)
+	}
+	if len(routes) != 1 || routes[0].Path != "/api/v1/posts/{id}" {
+		t.Errorf("Unexpected routes: %+v", routes)
+	}
+}
+
+func TestLoadRoutes_Resource(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.MkdirAll("routes", 0755)
+	os.WriteFile(filepath.Join("routes", "web.go"), []byte(`package routes
+
+import (
+	"example.com/app/app/http/controllers"
+
+	"github.com/velocitykode/velocity/pkg/router"
+)
+
+func init() {
+	router.Register(func(r router.Router) {
+		posts := &controllers.PostController{}
+		r.Resource("/posts", posts)
+
+		api := r.Group("/api")
+		api.Resource("/photos", controllers.PhotoController{}).Only("index", "show", "destroy").Except("destroy")
+		api.Any("/webhook", webhook)
+		api.Get("/broken")
+	})
+}
+`), 0644)
+
+	routes, unresolved, err := loadRoutes("routes", "main.go")
+	if err != nil {
+		t.Fatalf("loadRoutes() error = %v", err)
+	}
+
+	// Resource is accepted by the framework but registers nothing, so
+	// listing its routes would advertise endpoints that do not exist
+	if len(routes) != 0 {
+		t.Errorf("loadRoutes() = %+v, want no routes", routes)
+	}
+
+	file := filepath.Join("routes", "web.go")
+	want := []string{
+		file + `:12:3: r.Resource("/posts", posts): Resource registers no routes in this framework version`,
+		file + `:15:3: api.Resource("/photos", controllers.PhotoController{}).Only("index", "show", "destroy").Except("destroy"): Resource registers no routes in this framework version`,
+		file + `:16:3: api.Any("/webhook", webhook): the route could not be read from the source`,
+		file + `:17:3: api.Get("/broken"): the route could not be read from the source`,
+	}
+	if !reflect.DeepEqual(unresolved, want) {
+		t.Errorf("unresolved = %q, want %q", unresolved, want)
+	}
+}
+
+func TestLoadRoutes_Prefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.MkdirAll("routes", 0755)
+	os.WriteFile(filepath.Join("routes", "api.go"), []byte(`package routes
+
+import "github.com/velocitykode/velocity/pkg/router"
+
+func init() {
+	router.Register(func(r router.Router) {
+		r.Get("/health", health)
+		r.Prefix("/api")
+		r.Get("/users", users)
+
+		v2 := r.Group("/v2")
+		v2.Prefix("/beta")
+		v2.Get("/posts", posts)
+	})
+}
+`), 0644)
+
+	routes, unresolved, err := loadRoutes("routes", "main.go")
+	if err != nil {
+		t.Fatalf("loadRoutes() error = %v", err)
+	}
+
+	// Prefix replaces the prefix for the routes registered after it
+	expected := []routeInfo{
+		{"GET", "/api/users", "", "users", []string{}},
+		{"GET", "/beta/posts", "", "posts", []string{}},
+		{"GET", "/health", "", "health", []string{}},
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("loadRoutes() =\n%+v\nwant\n%+v", routes, expected)
+	}
+	if len(unresolved) != 0 {
+		t.Errorf("unresolved = %q, want none", unresolved)
+	}
+}
+
+func TestLoadRoutes_UseReturnsRouter(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.MkdirAll("routes", 0755)
+	os.WriteFile(filepath.Join("routes", "web.go"), []byte(`package routes
+
+import "github.com/velocitykode/velocity/pkg/router"
+
+func init() {
+	router.Register(func(r router.Router) {
+		r.Get("/before", before)
+		admin := r.Use(auth)
+		admin.Get("/admin", dashboard)
+		r.Get("/after", after)
+	})
+}
+`), 0644)
+
+	routes, _, err := loadRoutes("routes", "main.go")
+	if err != nil {
+		t.Fatalf("loadRoutes() error = %v", err)
+	}
+
+	// Use adds to the router it is called on, not to a copy of it
+	expected := []routeInfo{
+		{"GET", "/admin", "", "dashboard", []string{"auth"}},
+		{"GET", "/after", "", "after", []string{"auth"}},
+		{"GET", "/before", "", "before", []string{}},
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("loadRoutes() =\n%+v\nwant\n%+v", routes, expected)
+	}
+}
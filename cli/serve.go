@@ -2,15 +2,21 @@ package cli
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
-	"strings"
+	"slices"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/config"
 	"github.com/velocitykode/velocity-cli/internal/ui"
 )
 
@@ -19,6 +25,15 @@ var (
 	serveEnv       string
 	serveWatch     bool
 	serveBuildTags string
+	serveProxy     bool
+	serveAppPort   string
+	serveEnvFiles  []string
+	serveDebug     bool
+	serveDebugPort string
+	serveRace      bool
+	serveCover     bool
+	servePprof     bool
+	serveLogFormat string
 )
 
 var serveCmd = &cobra.Command{
@@ -26,7 +41,81 @@ var serveCmd = &cobra.Command{
 	Short: "Start the development server",
 	Long: `Start the Velocity development server with optional hot reload.
 
-The server will automatically reload when Go files change if --watch is enabled.`,
+The server will automatically reload when Go files change if --watch is enabled.
+A build that fails leaves the previous server running until the errors are
+fixed.
+Which files are watched, and whether a change rebuilds or only restarts the
+server, can be configured in velocity.yaml:
+
+  serve:
+    watch:
+      include: ["app/**", "routes/**", "resources/views/**", "*.go", ".env"]
+      exclude: ["storage", "*_test.go"]
+      extensions: [".html", ".tmpl", ".yaml"]
+      rules:
+        - pattern: "resources/views/**"
+          action: restart
+        - pattern: ".env"
+          action: restart
+
+On reload the old server gets SIGTERM and grace_period (default 5s) to
+exit before it is killed. The new server is reported ready once it answers
+on health_path, or accepts connections when no health_path is set:
+
+  serve:
+    grace_period: 10s
+    health_path: /health
+    ready_timeout: 15s
+
+With --proxy the CLI listens on --port and forwards to the app on an
+internal port. Requests made while the app restarts wait for it instead of
+failing, and HTML pages reload in the browser after each restart.
+
+Other processes, such as the frontend dev server or a queue worker, run
+alongside the app with their output labelled by name. Without a processes
+list the frontend dev server is started when package.json has a dev script;
+an empty list starts nothing:
+
+  serve:
+    processes:
+      - name: vite
+        command: bun run dev
+      - name: worker
+        command: go run ./cmd/worker
+        restart: always      # always, on-failure (default) or never
+        max_restarts: 5
+
+Ctrl-C stops every process, with the same grace period as the app.
+
+The app's environment is loaded from .env, .env.<env> and .env.local, then
+any --env-file, each overriding the last; variables already set in the shell
+win over all of them. Every key in .env.example must be set. With --watch,
+changing an env file restarts the app with the new values.
+
+With --debug the app is built without optimisations and run under a
+headless Delve server on --debug-port. Rebuilds are restarted inside the
+same debugger, so attached editors keep their session and breakpoints;
+a changed environment relaunches the debugger, as Delve restarts the app
+with the environment it was started with.
+
+--race builds with the race detector. --cover builds with coverage and
+points GOCOVERDIR at .velocity/coverage; data is written when the app exits
+normally. --pprof compiles a pprof server into the app on a private port,
+without changing its code; press p for a 30s CPU profile or h for a heap
+profile, saved in .velocity/profiles.
+
+While serve runs in a terminal, keys act on the session:
+
+  r  rebuild and restart now      o  open the app in the browser
+  c  clear the screen             l  show only warnings and errors
+  m  run pending migrations       s  show the last stack trace in full
+  q  quit
+
+The app's logs are pretty-printed by default: JSON, logfmt and the
+framework's console lines get aligned, level-coloured output, request
+durations are coloured by how long they took and stack traces collapse to
+the frame that caused them. --log-format json rewrites them as one JSON
+object per line, and raw leaves the output untouched.`,
 	RunE: runServe,
 }
 
@@ -35,10 +124,23 @@ func init() {
 	serveCmd.Flags().StringVarP(&serveEnv, "env", "e", "development", "Environment to run in")
 	serveCmd.Flags().BoolVarP(&serveWatch, "watch", "w", true, "Enable hot reload")
 	serveCmd.Flags().StringVar(&serveBuildTags, "tags", "", "Build tags to pass to go build")
+	serveCmd.Flags().BoolVar(&serveProxy, "proxy", false, "Serve through a reverse proxy with browser live-reload")
+	serveCmd.Flags().StringVar(&serveAppPort, "app-port", "", "Internal port for the app behind --proxy (default: a free port)")
+	serveCmd.Flags().BoolVar(&serveDebug, "debug", false, "Run the app under a headless Delve debugger")
+	serveCmd.Flags().StringVar(&serveDebugPort, "debug-port", "2345", "Port the Delve debugger listens on")
+	serveCmd.Flags().BoolVar(&serveRace, "race", false, "Build with the race detector")
+	serveCmd.Flags().BoolVar(&serveCover, "cover", false, "Build with coverage, writing data to "+coverageDir)
+	serveCmd.Flags().BoolVar(&servePprof, "pprof", false, "Serve pprof from the app and capture profiles from the terminal")
+	serveCmd.Flags().StringVar(&serveLogFormat, "log-format", logFormatPretty, "How to show the app's logs: raw, pretty or json")
+	serveCmd.Flags().StringSliceVar(&serveEnvFiles, "env-file", nil, "Extra env file to load after .env, .env.<env> and .env.local (repeatable)")
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
 	ui.Header("serve")
+	if err := validateLogFormat(serveLogFormat); err != nil {
+		ui.Error(err.Error())
+		return err
+	}
 	if serveWatch {
 		ui.Info(fmt.Sprintf("Starting on port %s with hot reload...", ui.Highlight(servePort)))
 	} else {
@@ -49,6 +151,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if serveWatch {
 		return runWithWatcher()
 	}
+	if serveProxy {
+		ui.Warning("--proxy only applies with --watch, serving directly")
+	}
 	return runServer()
 }
 
@@ -59,13 +164,26 @@ func runServer() error {
 	// Ensure build directory exists
 	os.MkdirAll(".velocity/tmp", 0755)
 
-	buildArgs := []string{"build", "-o", ".velocity/tmp/server"}
-	if serveBuildTags != "" {
-		buildArgs = append(buildArgs, "-tags", serveBuildTags)
+	dlv, err := debuggerPath()
+	if err != nil {
+		return err
+	}
+
+	modeEnv, err := prepareServeModes()
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	defer reportCoverage()
+
+	env, loaded, err := loadEnvironment(serveEnv, serveEnvFiles, modeEnv...)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
 	}
-	buildArgs = append(buildArgs, ".")
+	reportEnvFiles(loaded)
 
-	buildCmd := exec.Command("go", buildArgs...)
+	buildCmd := exec.Command("go", serveBuildArgs(serverBinary)...)
 	buildCmd.Stdout = os.Stdout
 	buildCmd.Stderr = os.Stderr
 
@@ -74,12 +192,27 @@ func runServer() error {
 		return err
 	}
 
-	serverCmd := exec.Command(".velocity/tmp/server")
-	serverCmd.Stdout = os.Stdout
-	serverCmd.Stderr = os.Stderr
-	serverCmd.Env = os.Environ()
+	if serveDebug {
+		printDebugAttach(serveDebugPort)
+	}
 
-	if err := serverCmd.Run(); err != nil {
+	var stacks stackTraces
+	serverCmd := serverCommand(dlv)
+	serverCmd.Stdout = newLogWriter(os.Stdout, serveLogFormat, nil, &stacks)
+	serverCmd.Stderr = newLogWriter(os.Stderr, serveLogFormat, nil, &stacks)
+	serverCmd.Env = env
+
+	appURL := "http://localhost:" + servePort
+	stopKeys := watchKeys(append([]keyBinding{
+		{key: "c", help: "clear", run: clearScreen},
+		{key: "o", help: "open", run: func() { openInBrowser(appURL) }},
+		{key: "s", help: "show stack", run: stacks.show},
+	}, profileKeys(modeEnv)...))
+	defer stopKeys()
+
+	err = serverCmd.Run()
+	flushOutput(serverCmd)
+	if err != nil {
 		ui.Error(fmt.Sprintf("Server failed: %v", err))
 		return err
 	}
@@ -89,105 +222,361 @@ func runServer() error {
 func runWithWatcher() error {
 	os.MkdirAll(".velocity/tmp", 0755)
 
-	rebuild := make(chan bool, 1)
+	project, err := config.LoadProject(".")
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	matcher := newWatchMatcher(envWatch(project.Serve.Watch, serveEnv, serveEnvFiles))
+
+	env, loaded, err := loadEnvironment(serveEnv, serveEnvFiles)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	reportEnvFiles(loaded)
+
+	dlv, err := debuggerPath()
+	if err != nil {
+		return err
+	}
+	if serveDebug {
+		printDebugAttach(serveDebugPort)
+	}
+	var debug debugSession
+	defer debug.close()
+
+	modeEnv, err := prepareServeModes()
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	defer reportCoverage()
+
+	changes := make(chan string, 1)
 	errChan := make(chan error, 1)
 
 	go func() {
-		if err := watchFiles(rebuild); err != nil {
+		if err := watchFiles(matcher, changes); err != nil {
 			errChan <- err
 		}
 	}()
 
-	var serverCmd *exec.Cmd
+	grace, readyTimeout := serveTimings(project.Serve)
+
+	// In proxy mode the app listens on an internal port behind the proxy
+	appPort := servePort
+	var proxy *devProxy
+	if serveProxy {
+		appPort = serveAppPort
+		if appPort == "" {
+			if appPort, err = freePort(); err != nil {
+				ui.Error(fmt.Sprintf("Failed to find a port for the app: %v", err))
+				return err
+			}
+		}
+
+		target, err := url.Parse("http://127.0.0.1:" + appPort)
+		if err != nil {
+			return err
+		}
+		proxy = newDevProxy(target, grace+portFreeTimeout+readyTimeout)
+
+		ln, err := net.Listen("tcp", ":"+servePort)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Failed to start proxy: %v", err))
+			return err
+		}
+		proxyServer := &http.Server{Handler: proxy}
+		defer proxyServer.Close()
+		go func() {
+			if err := proxyServer.Serve(ln); err != http.ErrServerClosed {
+				errChan <- err
+			}
+		}()
+	}
+
+	var server *serverProcess
+	var serverEnv []string // Environment the running server started with
+	var started bool
+	var fixed string // Reported once a broken build or environment is fixed
 	var mu sync.Mutex
 
-	startServer := func() error {
+	// While the build is broken the last good server keeps running and a
+	// status line stays at the bottom of the output
+	status := newStatusLine()
+	defer status.Clear()
+	serverOut := status.Writer(os.Stdout)
+	serverErr := status.Writer(os.Stderr)
+	filter := &logFilter{}
+	var stacks stackTraces
+
+	// Other processes run alongside the app, with each line of output
+	// labelled by the process it came from
+	processes := project.Serve.Processes
+	if processes == nil {
+		processes = defaultProcesses()
+	}
+	supervised := newSupervisor(grace, env)
+	if len(processes) > 0 {
+		names := []string{"app"}
+		for _, p := range processes {
+			names = append(names, p.Name)
+		}
+		writers := newPrefixWriters(serverOut, names)
+		serverOut, serverErr = writers["app"], writers["app"]
+		for _, p := range processes {
+			ui.Step(fmt.Sprintf("Starting %s: %s", p.Name, p.Command))
+			supervised.Start(p, writers[p.Name])
+		}
+	}
+	serverOut = newLogWriter(serverOut, serveLogFormat, filter, &stacks)
+	serverErr = newLogWriter(serverErr, serveLogFormat, filter, &stacks)
+
+	// Everything is stopped together however serve exits. Processes run
+	// in their own process groups, so Ctrl-C is handled here rather than
+	// reaching them directly.
+	defer func() {
 		mu.Lock()
 		defer mu.Unlock()
+		if server != nil {
+			server.stop(grace)
+		}
+		supervised.Stop()
+	}()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	// Keys act on the running session; a forced rebuild and quitting go
+	// through the same paths as a file change and Ctrl-C
+	forceRebuild := make(chan struct{}, 1)
+	var migrations migrator
+	appURL := "http://localhost:" + servePort
+	stopKeys := watchKeys(append([]keyBinding{
+		{key: "r", help: "rebuild", run: func() {
+			select {
+			case forceRebuild <- struct{}{}:
+			default:
+			}
+		}},
+		{key: "c", help: "clear", run: func() {
+			status.Hide()
+			clearScreen()
+			status.Show()
+		}},
+		{key: "m", help: "migrate", run: migrations.run},
+		{key: "o", help: "open", run: func() { openInBrowser(appURL) }},
+		{key: "l", help: "filter logs", run: func() {
+			if filter.toggle() {
+				ui.Info("Showing app warnings and errors only")
+			} else {
+				ui.Info("Showing all app logs")
+			}
+		}},
+		{key: "s", help: "show stack", run: func() {
+			status.Hide()
+			stacks.show()
+			status.Show()
+		}},
+		{key: "q", help: "quit", run: func() {
+			select {
+			case interrupt <- os.Interrupt:
+			default:
+			}
+		}},
+	}, profileKeys(modeEnv)...))
+	defer stopKeys()
+
+	// waitServer reports the server ready once it answers. A server that
+	// is slow or fails to come up is reported, not fatal; the next change
+	// restarts it.
+	waitServer := func() error {
+		if err := server.waitReady(appPort, project.Serve.HealthPath, readyTimeout); err != nil {
+			ui.Warning(err.Error())
+			return nil
+		}
+		ui.Success(fmt.Sprintf("Server ready on %s", ui.Highlight("http://localhost:"+servePort)))
 
-		if serverCmd != nil && serverCmd.Process != nil {
-			ui.Step("Stopping server...")
-			serverCmd.Process.Kill()
-			serverCmd.Wait()
+		if proxy != nil && started {
+			proxy.Reload()
 		}
+		started = true
+		return nil
+	}
+
+	// startServer restarts the server, rebuilding it first unless only a
+	// restart was asked for. A new binary is built before the old server
+	// is touched, so a failed build leaves it running. The old server is
+	// stopped gracefully and the new one is only reported ready once it
+	// answers.
+	startServer := func(rebuild bool) error {
+		mu.Lock()
+		defer mu.Unlock()
 
-		ui.Step("Building...")
-		buildArgs := []string{"build", "-o", ".velocity/tmp/server"}
-		if serveBuildTags != "" {
-			buildArgs = append(buildArgs, "-tags", serveBuildTags)
+		status.Hide()
+		defer status.Show()
+
+		if rebuild {
+			ui.Step("Building...")
+			if output, err := buildServerBinary(); err != nil {
+				reportBuildFailure(output)
+				if server != nil {
+					status.setText(ui.ErrorText("Build broken, still serving the last successful build"))
+					fixed = "Build fixed"
+				}
+				return err
+			}
 		}
-		buildArgs = append(buildArgs, ".")
 
-		buildCmd := exec.Command("go", buildArgs...)
-		if output, err := buildCmd.CombinedOutput(); err != nil {
-			ui.Error("Build failed:")
-			ui.Muted(string(output))
+		// The environment is read on every start so env file changes take
+		// effect; an invalid one also leaves the old server running
+		overrides := append([]string{"APP_ENV=" + serveEnv, "APP_PORT=" + appPort}, modeEnv...)
+		env, _, err := loadEnvironment(serveEnv, serveEnvFiles, overrides...)
+		if err != nil {
+			ui.Error(err.Error())
+			if server != nil {
+				status.setText(ui.ErrorText("Environment invalid, still serving with the previous one"))
+				fixed = "Environment fixed"
+			}
 			return err
 		}
 
-		ui.Success(fmt.Sprintf("Starting server on port %s...", servePort))
-		serverCmd = exec.Command(".velocity/tmp/server")
-		serverCmd.Stdout = os.Stdout
-		serverCmd.Stderr = os.Stderr
-		serverCmd.Env = append(os.Environ(),
-			fmt.Sprintf("APP_ENV=%s", serveEnv),
-			fmt.Sprintf("APP_PORT=%s", servePort),
-		)
+		if status.Text() != "" {
+			status.setText("")
+			ui.Success(fixed)
+		}
+
+		if proxy != nil {
+			proxy.Pause()
+			defer proxy.Resume()
+		}
 
-		if err := serverCmd.Start(); err != nil {
+		installed := false
+		if rebuild && serveDebug && server != nil && !slices.Equal(env, serverEnv) {
+			// Delve restarts the app with the environment it was launched
+			// with, so a changed one needs a new debugger
+			ui.Step("Environment changed, relaunching the debugger")
+		} else if rebuild && serveDebug && server != nil {
+			// Restart inside the running debugger so editors stay attached
+			if err := os.Rename(serverNextBinary, serverBinary); err != nil {
+				ui.Error(fmt.Sprintf("Failed to install server binary: %v", err))
+				return err
+			}
+			installed = true
+			if err := debug.restart(serveDebugPort); err != nil {
+				ui.Warning(fmt.Sprintf("Could not restart in the debugger, relaunching it: %v", err))
+			} else {
+				ui.Step("Restarted in the debugger")
+				return waitServer()
+			}
+		}
+
+		if server != nil {
+			debug.close()
+			ui.Step("Stopping server...")
+			if server.stop(grace) {
+				ui.Warning(fmt.Sprintf("Server did not exit within %s, killed it", grace))
+			}
+			server = nil
+			if err := waitPortFree(appPort, portFreeTimeout); err != nil {
+				ui.Warning(err.Error())
+			}
+		}
+
+		if rebuild && !installed {
+			if err := os.Rename(serverNextBinary, serverBinary); err != nil {
+				ui.Error(fmt.Sprintf("Failed to install server binary: %v", err))
+				return err
+			}
+		}
+
+		ui.Step(fmt.Sprintf("Starting server on port %s...", appPort))
+		serverCmd := serverCommand(dlv)
+		serverCmd.Stdout = serverOut
+		serverCmd.Stderr = serverErr
+		serverCmd.Env = env
+
+		if server, err = startProcess(serverCmd); err != nil {
 			ui.Error(fmt.Sprintf("Failed to start server: %v", err))
 			return err
 		}
-		return nil
+		serverEnv = env
+		return waitServer()
 	}
 
 	// Initial build and start
-	if err := startServer(); err != nil {
+	if err := startServer(true); err != nil {
 		return err
 	}
 
-	// Watch for rebuilds
+	// Watch for changes
 	for {
 		select {
 		case err := <-errChan:
 			return err
-		case <-rebuild:
+		case <-interrupt:
+			status.Clear()
 			ui.Newline()
-			ui.Warning("File changed, reloading...")
+			ui.Step("Shutting down...")
+			return nil
+		case <-forceRebuild:
+			status.Hide()
+			ui.Newline()
+			ui.Warning("Rebuilding...")
+			status.Show()
+			startServer(true)
+		case action := <-changes:
+			status.Hide()
+			ui.Newline()
+			if action == config.WatchRestart {
+				ui.Warning("File changed, restarting...")
+			} else {
+				ui.Warning("File changed, reloading...")
+			}
+			status.Show()
 			time.Sleep(100 * time.Millisecond)
-			startServer() // Ignore error on reload, keep watching
+			startServer(action == config.WatchRebuild) // Ignore error on reload, keep watching
 		}
 	}
 }
 
-func watchFiles(rebuild chan bool) error {
+// watchFiles watches the project for changes the matcher cares about and
+// sends the resulting action once changes settle. A rebuild wins over a
+// restart when both happen within the debounce window, or while a restart
+// is still waiting to be handled.
+func watchFiles(matcher *watchMatcher, changes chan string) error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return fmt.Errorf("failed to create watcher: %w", err)
 	}
 	defer watcher.Close()
 
-	err = filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if strings.Contains(path, "vendor") || strings.Contains(path, ".velocity") {
-			return filepath.SkipDir
-		}
-
-		if info.IsDir() {
-			return watcher.Add(path)
-		}
-
-		return nil
-	})
-
-	if err != nil {
+	if err := addWatchDirs(watcher, matcher, "."); err != nil {
 		return fmt.Errorf("failed to setup watcher: %w", err)
 	}
 
+	var mu sync.Mutex
 	var debounce *time.Timer
+	pending := ""
+
+	queue := func(action string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if pending != config.WatchRebuild {
+			pending = action
+		}
+		if debounce != nil {
+			debounce.Stop()
+		}
+		debounce = time.AfterFunc(500*time.Millisecond, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			action := pending
+			pending = ""
+			sendWatchAction(changes, action)
+		})
+	}
 
 	for {
 		select {
@@ -196,19 +585,23 @@ func watchFiles(rebuild chan bool) error {
 				return nil
 			}
 
-			if !strings.HasSuffix(event.Name, ".go") {
-				continue
+			// Watch directories created after startup, such as a new
+			// package, along with the files already in them
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchDirs(watcher, matcher, event.Name); err != nil {
+						ui.Error(fmt.Sprintf("Watcher error: %v", err))
+					}
+					if action := dirAction(matcher, event.Name); action != "" {
+						queue(action)
+					}
+					continue
+				}
 			}
 
-			if debounce != nil {
-				debounce.Stop()
+			if action := matcher.action(event.Name); action != "" {
+				queue(action)
 			}
-			debounce = time.AfterFunc(500*time.Millisecond, func() {
-				select {
-				case rebuild <- true:
-				default:
-				}
-			})
 
 		case err, ok := <-watcher.Errors:
 			if !ok {
@@ -218,3 +611,67 @@ func watchFiles(rebuild chan bool) error {
 		}
 	}
 }
+
+// sendWatchAction sends action without blocking. When an action is
+// already waiting it is replaced by the stronger of the two, so a rebuild
+// is never lost behind a restart. It relies on being the only sender.
+func sendWatchAction(changes chan string, action string) {
+	for {
+		select {
+		case changes <- action:
+			return
+		default:
+		}
+
+		select {
+		case waiting := <-changes:
+			if waiting == config.WatchRebuild {
+				action = config.WatchRebuild
+			}
+		default:
+		}
+	}
+}
+
+// dirAction returns the action for the files inside a directory that
+// appeared after startup, or "" when none of them matter
+func dirAction(matcher *watchMatcher, root string) string {
+	action := ""
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if matcher.excluded(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch matcher.action(path) {
+		case config.WatchRebuild:
+			action = config.WatchRebuild
+			return filepath.SkipAll
+		case config.WatchRestart:
+			action = config.WatchRestart
+		}
+		return nil
+	})
+	return action
+}
+
+// addWatchDirs watches root and every directory below it that is not
+// excluded
+func addWatchDirs(watcher *fsnotify.Watcher, matcher *watchMatcher, root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if matcher.excluded(path) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+	"golang.org/x/term"
+)
+
+// Server binaries used by serve. Builds go to serverNextBinary and only
+// replace serverBinary once they succeed.
+const (
+	serverBinary     = ".velocity/tmp/server"
+	serverNextBinary = ".velocity/tmp/server.next"
+)
+
+// compileError is one error reported by go build
+type compileError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Location returns file:line[:column]
+func (e compileError) Location() string {
+	location := fmt.Sprintf("%s:%d", e.File, e.Line)
+	if e.Column > 0 {
+		location += fmt.Sprintf(":%d", e.Column)
+	}
+	return location
+}
+
+var compileErrorLine = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// parseBuildErrors extracts file:line errors from go build output.
+// Indented lines continue the previous error; package headers and other
+// lines are dropped.
+func parseBuildErrors(output string) []compileError {
+	var errs []compileError
+	for _, line := range strings.Split(output, "\n") {
+		if m := compileErrorLine.FindStringSubmatch(line); m != nil {
+			lineNo, _ := strconv.Atoi(m[2])
+			column, _ := strconv.Atoi(m[3])
+			errs = append(errs, compileError{
+				File:    strings.TrimPrefix(m[1], "./"),
+				Line:    lineNo,
+				Column:  column,
+				Message: m[4],
+			})
+			continue
+		}
+		if len(errs) > 0 && strings.HasPrefix(line, "\t") {
+			last := &errs[len(errs)-1]
+			last.Message += "\n" + line
+		}
+	}
+	return errs
+}
+
+// buildServerBinary builds the project into serverNextBinary, returning
+// the compiler output when it fails
+func buildServerBinary() (string, error) {
+	output, err := exec.Command("go", serveBuildArgs(serverNextBinary)...).CombinedOutput()
+	if err != nil {
+		os.Remove(serverNextBinary)
+		return string(output), err
+	}
+	return "", nil
+}
+
+// reportBuildFailure prints the compile errors in output, or the raw
+// output when none can be parsed
+func reportBuildFailure(output string) {
+	errs := parseBuildErrors(output)
+	if len(errs) == 0 {
+		ui.Error("Build failed:")
+		ui.Muted(output)
+		return
+	}
+
+	ui.Error(fmt.Sprintf("Build failed (%d errors):", len(errs)))
+	for _, e := range errs {
+		message := strings.ReplaceAll(e.Message, "\n", "\n    ")
+		fmt.Printf("  %s  %s\n", ui.Highlight(e.Location()), message)
+	}
+}
+
+// statusLine keeps a status message on the last line of a terminal while
+// other output scrolls above it. Off a terminal the status is printed
+// once each time it changes. The lock is only held while printing, never
+// while the status is hidden, so output keeps flowing during a restart.
+type statusLine struct {
+	mu      sync.Mutex
+	out     io.Writer
+	tty     bool
+	text    string
+	printed string
+	hidden  int  // Hide calls not yet matched by Show
+	drawn   bool // The status is on the terminal's last line
+}
+
+// newStatusLine returns a status line on stdout
+func newStatusLine() *statusLine {
+	return &statusLine{out: os.Stdout, tty: term.IsTerminal(int(os.Stdout.Fd()))}
+}
+
+// Hide erases the status so other output can be printed. The status stays
+// hidden until every Hide is matched by a Show.
+func (s *statusLine) Hide() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hidden++
+	s.erase()
+}
+
+// Show redraws the status after Hide
+func (s *statusLine) Show() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.hidden > 0 {
+		s.hidden--
+	}
+	if s.hidden == 0 {
+		s.draw()
+	}
+}
+
+// setText changes the status, shown the next time it is drawn
+func (s *statusLine) setText(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.text = text
+}
+
+// Text returns the current status
+func (s *statusLine) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.text
+}
+
+// Clear removes the status
+func (s *statusLine) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.erase()
+	s.text = ""
+}
+
+// Writer returns a writer for output that should scroll above the status
+func (s *statusLine) Writer(w io.Writer) io.Writer {
+	return statusWriter{status: s, out: w}
+}
+
+func (s *statusLine) erase() {
+	if s.drawn {
+		fmt.Fprint(s.out, "\r\033[2K")
+		s.drawn = false
+	}
+}
+
+func (s *statusLine) draw() {
+	switch {
+	case s.tty:
+		fmt.Fprint(s.out, s.text)
+		s.drawn = s.text != ""
+	case s.text != s.printed && s.text != "":
+		fmt.Fprintln(s.out, s.text)
+	}
+	s.printed = s.text
+}
+
+// statusWriter writes through to out, redrawing the status after each
+// complete line
+type statusWriter struct {
+	status *statusLine
+	out    io.Writer
+}
+
+func (w statusWriter) Write(p []byte) (int, error) {
+	w.status.mu.Lock()
+	defer w.status.mu.Unlock()
+
+	w.status.erase()
+	n, err := w.out.Write(p)
+	if w.status.tty && w.status.hidden == 0 && len(p) > 0 && p[len(p)-1] == '\n' {
+		w.status.draw()
+	}
+	return n, err
+}
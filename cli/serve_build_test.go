@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseBuildErrors(t *testing.T) {
+	output := `# testserve/app/controllers
+app/controllers/home.go:12:5: undefined: foo
+./main.go:7:2: "fmt" imported and not used
+routes/web.go:20: syntax error: unexpected newline
+main.go:9:10: cannot use x (variable of type int) as string value in argument to f:
+	have (int)
+	want (string)
+note: module requires Go 1.22
+`
+
+	want := []compileError{
+		{File: "app/controllers/home.go", Line: 12, Column: 5, Message: "undefined: foo"},
+		{File: "main.go", Line: 7, Column: 2, Message: `"fmt" imported and not used`},
+		{File: "routes/web.go", Line: 20, Message: "syntax error: unexpected newline"},
+		{File: "main.go", Line: 9, Column: 10, Message: "cannot use x (variable of type int) as string value in argument to f:\n\thave (int)\n\twant (string)"},
+	}
+
+	got := parseBuildErrors(output)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBuildErrors() = %+v, want %+v", got, want)
+	}
+
+	if loc := got[0].Location(); loc != "app/controllers/home.go:12:5" {
+		t.Errorf("Location() = %q", loc)
+	}
+	if loc := got[2].Location(); loc != "routes/web.go:20" {
+		t.Errorf("Location() = %q", loc)
+	}
+}
+
+func TestBuildServerBinary_KeepsLastGoodBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	serveBuildTags = ""
+	os.MkdirAll(".velocity/tmp", 0755)
+	os.WriteFile(serverBinary, []byte("last good build"), 0755)
+	os.WriteFile("go.mod", []byte("module testserve\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {\n\tundefinedCall()\n}\n"), 0644)
+
+	output, err := buildServerBinary()
+	if err == nil {
+		t.Fatal("buildServerBinary() should fail on a compile error")
+	}
+	errs := parseBuildErrors(output)
+	if len(errs) != 1 || errs[0].File != "main.go" || errs[0].Line != 4 {
+		t.Errorf("parsed errors = %+v from %q", errs, output)
+	}
+
+	if content, _ := os.ReadFile(serverBinary); string(content) != "last good build" {
+		t.Error("a failed build should not replace the server binary")
+	}
+	if _, err := os.Stat(serverNextBinary); !os.IsNotExist(err) {
+		t.Error("a failed build should not leave a partial binary")
+	}
+
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+	if output, err := buildServerBinary(); err != nil {
+		t.Fatalf("buildServerBinary() error = %v: %s", err, output)
+	}
+	if _, err := os.Stat(serverNextBinary); err != nil {
+		t.Error("a successful build should produce the next binary")
+	}
+}
+
+func TestStatusLine_NotTerminal(t *testing.T) {
+	var out bytes.Buffer
+	status := &statusLine{out: &out}
+	w := status.Writer(&out)
+
+	status.Hide()
+	status.setText("build broken")
+	status.Show()
+	w.Write([]byte("server log\n"))
+
+	status.Hide()
+	status.Show()
+
+	if got, want := out.String(), "build broken\nserver log\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestStatusLine_Terminal(t *testing.T) {
+	var out bytes.Buffer
+	status := &statusLine{out: &out, tty: true}
+	w := status.Writer(&out)
+
+	status.Hide()
+	status.setText("build broken")
+	status.Show()
+	w.Write([]byte("server log\n"))
+	status.Clear()
+
+	want := "build broken" + "\r\033[2K" + "server log\n" + "build broken" + "\r\033[2K"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestStatusLine_WriteWhileHidden(t *testing.T) {
+	var out bytes.Buffer
+	status := &statusLine{out: &out, tty: true}
+	w := status.Writer(&out)
+
+	status.setText("build broken")
+	status.Hide()
+
+	// A server printing while it shuts down must not wait for Show
+	done := make(chan struct{})
+	go func() {
+		w.Write([]byte("shutting down\n"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Write blocked while the status was hidden")
+	}
+
+	status.Show()
+	if got, want := out.String(), "shutting down\n"+"build broken"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// debugGCFlags disable optimisations and inlining so every variable and
+// line can be inspected in the debugger
+const debugGCFlags = "all=-N -l"
+
+// serveBuildArgs returns the go build arguments for the server binary
+func serveBuildArgs(output string) []string {
+	args := []string{"build", "-o", output}
+	if serveBuildTags != "" {
+		args = append(args, "-tags", serveBuildTags)
+	}
+	if serveDebug {
+		args = append(args, "-gcflags", debugGCFlags)
+	}
+	args = append(args, serveModeBuildArgs()...)
+	return append(args, ".")
+}
+
+// serverCommand returns the command that runs the server binary, under a
+// headless Delve server with --debug
+func serverCommand(dlv string) *exec.Cmd {
+	if !serveDebug {
+		return exec.Command(serverBinary)
+	}
+	return exec.Command(dlv, "exec", serverBinary,
+		"--headless",
+		"--listen=127.0.0.1:"+serveDebugPort,
+		"--api-version=2",
+		"--accept-multiclient",
+		"--continue",
+	)
+}
+
+// findDelve returns the path of the dlv binary, looking in GOPATH/bin
+// when it is not on PATH
+func findDelve() (string, error) {
+	if path, err := exec.LookPath("dlv"); err == nil {
+		return path, nil
+	}
+
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			gopath = filepath.Join(home, "go")
+		}
+	}
+	path := filepath.Join(gopath, "bin", "dlv")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	return "", fmt.Errorf("dlv not found, install it with: go install github.com/go-delve/delve/cmd/dlv@latest")
+}
+
+// debuggerPath returns the dlv binary when --debug is set
+func debuggerPath() (string, error) {
+	if !serveDebug {
+		return "", nil
+	}
+	dlv, err := findDelve()
+	if err != nil {
+		ui.Error(err.Error())
+	}
+	return dlv, err
+}
+
+// printDebugAttach shows how to attach editors to the debugger
+func printDebugAttach(port string) {
+	ui.Info(fmt.Sprintf("Debugger listening on %s", ui.Highlight("127.0.0.1:"+port)))
+	ui.Muted("Attach with: dlv connect 127.0.0.1:" + port)
+	ui.Muted("VS Code (.vscode/launch.json):")
+	ui.Muted(fmt.Sprintf(`  {"name": "Attach to velocity serve", "type": "go", "request": "attach", "mode": "remote", "host": "127.0.0.1", "port": %s}`, port))
+	ui.Muted(fmt.Sprintf("GoLand: Run > Edit Configurations > Go Remote, host 127.0.0.1, port %s", port))
+	ui.Newline()
+}
+
+// debugSession talks to a running Delve server over its JSON-RPC API
+type debugSession struct {
+	client *rpc.Client
+}
+
+// dlvRestartIn mirrors the arguments of Delve's RPCServer.Restart
+type dlvRestartIn struct {
+	Position  string
+	ResetArgs bool
+	NewArgs   []string
+	Rerecord  bool
+	Rebuild   bool
+}
+
+// dlvCommand mirrors Delve's api.DebuggerCommand
+type dlvCommand struct {
+	Name string `json:"name"`
+}
+
+// restart relaunches the debugged binary inside Delve, so connected
+// editors keep their session and breakpoints, and lets it run
+func (d *debugSession) restart(port string) error {
+	if d.client == nil {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", port), 2*time.Second)
+		if err != nil {
+			return err
+		}
+		d.client = jsonrpc.NewClient(conn)
+	}
+
+	var out map[string]any
+	if err := d.client.Call("RPCServer.Restart", dlvRestartIn{}, &out); err != nil {
+		d.close()
+		return fmt.Errorf("restart: %w", err)
+	}
+
+	// continue only returns once the program stops again, so it is left
+	// pending on this connection
+	d.client.Go("RPCServer.Command", dlvCommand{Name: "continue"}, &map[string]any{}, nil)
+	return nil
+}
+
+// close drops the connection to Delve
+func (d *debugSession) close() {
+	if d.client != nil {
+		d.client.Close()
+		d.client = nil
+	}
+}
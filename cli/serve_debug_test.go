@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestServeBuildArgs(t *testing.T) {
+	defer func() { serveDebug, serveBuildTags = false, "" }()
+
+	serveDebug, serveBuildTags = false, ""
+	if got, want := serveBuildArgs("out"), []string{"build", "-o", "out", "."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("serveBuildArgs() = %v, want %v", got, want)
+	}
+
+	serveDebug, serveBuildTags = true, "integration"
+	want := []string{"build", "-o", "out", "-tags", "integration", "-gcflags", "all=-N -l", "."}
+	if got := serveBuildArgs("out"); !reflect.DeepEqual(got, want) {
+		t.Errorf("serveBuildArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestServerCommand_Debug(t *testing.T) {
+	defer func() { serveDebug, serveDebugPort = false, "2345" }()
+
+	serveDebug = false
+	if cmd := serverCommand(""); !reflect.DeepEqual(cmd.Args, []string{serverBinary}) {
+		t.Errorf("args = %v, want the server binary alone", cmd.Args)
+	}
+
+	serveDebug, serveDebugPort = true, "4567"
+	cmd := serverCommand("/usr/local/bin/dlv")
+	want := []string{"/usr/local/bin/dlv", "exec", serverBinary, "--headless", "--listen=127.0.0.1:4567",
+		"--api-version=2", "--accept-multiclient", "--continue"}
+	if !reflect.DeepEqual(cmd.Args, want) {
+		t.Errorf("args = %v, want %v", cmd.Args, want)
+	}
+}
+
+// fakeDelve records the JSON-RPC calls a debugSession makes. net/rpc
+// needs exported argument types, so Delve's are mirrored here.
+type fakeDelve struct {
+	calls chan string
+}
+
+type RestartIn struct {
+	Rebuild bool
+}
+
+type DebuggerCommand struct {
+	Name string `json:"name"`
+}
+
+func (d *fakeDelve) Restart(in RestartIn, out *map[string]any) error {
+	d.calls <- "Restart"
+	return nil
+}
+
+func (d *fakeDelve) Command(in DebuggerCommand, out *map[string]any) error {
+	d.calls <- "Command:" + in.Name
+	return nil
+}
+
+func TestDebugSession_Restart(t *testing.T) {
+	fake := &fakeDelve{calls: make(chan string, 4)}
+	server := rpc.NewServer()
+	if err := server.RegisterName("RPCServer", fake); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	var session debugSession
+	defer session.close()
+	if err := session.restart(port); err != nil {
+		t.Fatalf("restart() error = %v", err)
+	}
+
+	for _, want := range []string{"Restart", "Command:continue"} {
+		select {
+		case got := <-fake.calls:
+			if got != want {
+				t.Errorf("call = %q, want %q", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Delve never received %s", want)
+		}
+	}
+}
+
+func TestDebugSession_RestartUnavailable(t *testing.T) {
+	port, err := freePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var session debugSession
+	if err := session.restart(port); err == nil {
+		t.Error("restart() should fail when no debugger is listening")
+	}
+}
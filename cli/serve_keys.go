@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// clearScreen clears the terminal and its scrollback
+func clearScreen() {
+	fmt.Print("\033[H\033[2J\033[3J")
+}
+
+// openInBrowser opens url, reporting a failure rather than returning it
+func openInBrowser(url string) {
+	if err := openBrowser(url); err != nil {
+		ui.Warning(fmt.Sprintf("Could not open a browser: %v", err))
+		return
+	}
+	ui.Step("Opened " + url)
+}
+
+// openBrowser opens url in the default browser without waiting for it
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// migrator runs the project's pending migrations, one run at a time
+type migrator struct {
+	running atomic.Bool
+}
+
+// run migrates in the background through the project CLI, which is
+// rebuilt by go run so migrations added since serve started are included
+func (m *migrator) run() {
+	if !m.running.CompareAndSwap(false, true) {
+		ui.Warning("Migrations are already running")
+		return
+	}
+	if _, err := os.Stat("cmd/velocity"); err != nil {
+		m.running.Store(false)
+		ui.Warning("No project CLI in cmd/velocity to run migrations with")
+		return
+	}
+
+	env, _, err := loadEnvironment(serveEnv, serveEnvFiles, "APP_ENV="+serveEnv)
+	if err != nil {
+		m.running.Store(false)
+		ui.Error(err.Error())
+		return
+	}
+
+	ui.Step("Running pending migrations...")
+	go func() {
+		defer m.running.Store(false)
+
+		cmd := exec.Command("go", "run", "./cmd/velocity", "migrate")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = env
+		if err := cmd.Run(); err != nil {
+			ui.Error(fmt.Sprintf("Migrations failed: %v", err))
+		}
+	}()
+}
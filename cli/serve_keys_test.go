@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyModel_DispatchesBindings(t *testing.T) {
+	var pressed []string
+	m := keyModel{bindings: []keyBinding{
+		{key: "r", help: "rebuild", run: func() { pressed = append(pressed, "r") }},
+		{key: "q", help: "quit", run: func() { pressed = append(pressed, "q") }},
+	}}
+
+	for _, r := range "rxq" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m.Update(tea.WindowSizeMsg{Width: 80})
+
+	if len(pressed) != 2 || pressed[0] != "r" || pressed[1] != "q" {
+		t.Errorf("pressed = %v, want [r q]", pressed)
+	}
+	if m.View() != "" {
+		t.Error("the key model should render nothing")
+	}
+}
+
+func TestKeyHelp(t *testing.T) {
+	got := keyHelp([]keyBinding{{key: "r", help: "rebuild"}, {key: "q", help: "quit"}})
+	if got != "r rebuild · q quit" {
+		t.Errorf("keyHelp() = %q", got)
+	}
+}
+
+func TestWatchKeys_NotATerminal(t *testing.T) {
+	// Test stdin is never a terminal, so nothing should be read
+	stop := watchKeys([]keyBinding{{key: "q", help: "quit", run: func() {}}})
+	stop()
+}
+
+func TestProfileKeys(t *testing.T) {
+	if keys := profileKeys(nil); keys != nil {
+		t.Errorf("profileKeys() without --pprof = %v, want none", keys)
+	}
+
+	keys := profileKeys([]string{"GOCOVERDIR=/tmp", pprofAddrEnv + "=127.0.0.1:6060"})
+	if len(keys) != 2 || keys[0].key != "p" || keys[1].key != "h" {
+		t.Errorf("profileKeys() = %v, want p and h", keys)
+	}
+}
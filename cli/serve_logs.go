@@ -0,0 +1,580 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/velocitykode/velocity-cli/internal/colors"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// Formats for the app's log output
+const (
+	logFormatRaw    = "raw"    // Exactly as the app wrote it
+	logFormatPretty = "pretty" // Structured lines aligned and coloured
+	logFormatJSON   = "json"   // Structured lines as one JSON object each
+)
+
+// stackIdleTime is how long a stack trace may pause before it is taken as
+// complete, since the app may write nothing after it
+const stackIdleTime = 200 * time.Millisecond
+
+// validateLogFormat checks a --log-format value
+func validateLogFormat(format string) error {
+	switch format {
+	case logFormatRaw, logFormatPretty, logFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid log format: %q (expected raw, pretty or json)", format)
+}
+
+// logRecord is a structured log line
+type logRecord struct {
+	Time    string
+	Level   string // One of the level constants, or "" when unknown
+	Message string
+	Fields  []logField
+}
+
+// logField is a key/value pair of a log record, in the order written
+type logField struct {
+	Key   string
+	Value string
+}
+
+var (
+	// [15:04:05] INFO: Server starting | port=4000, as written by the
+	// framework's console logger
+	consoleLogPattern = regexp.MustCompile(`^\[([^\]]+)\] ([A-Z]+): (.*)$`)
+	consoleKeyPattern = regexp.MustCompile(` ([A-Za-z_][\w.-]*)=`)
+
+	// The first line of each goroutine in a stack trace
+	goroutinePattern = regexp.MustCompile(`^goroutine \d+ \[.*\]:$`)
+	// A function in a stack trace, such as net/http.(*conn).serve(0xc0001)
+	stackFuncPattern = regexp.MustCompile(`^[\w./*()\[\]{}-]+\(.*\)$`)
+)
+
+// parseLogLine recognises JSON, logfmt and the framework's console format
+func parseLogLine(line string) (logRecord, bool) {
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLog(line)
+	}
+	if m := consoleLogPattern.FindStringSubmatch(line); m != nil {
+		return parseConsoleLog(m[1], m[2], m[3]), true
+	}
+	return parseLogfmt(line)
+}
+
+// parseJSONLog reads a JSON object, keeping its keys in order
+func parseJSONLog(line string) (logRecord, bool) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return logRecord{}, false
+	}
+
+	var fields []logField
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return logRecord{}, false
+		}
+		key, _ := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return logRecord{}, false
+		}
+		value := string(raw)
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			value = s
+		}
+		fields = append(fields, logField{Key: key, Value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return logRecord{}, false
+	}
+	return newLogRecord(fields)
+}
+
+// parseLogfmt reads key=value pairs, where values may be quoted. Every
+// token must be a pair for the line to count as logfmt.
+func parseLogfmt(line string) (logRecord, bool) {
+	var fields []logField
+	rest := strings.TrimSpace(line)
+	for rest != "" {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t\"") {
+			return logRecord{}, false
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			end := 1
+			for end < len(value) && (value[end] != '"' || value[end-1] == '\\') {
+				end++
+			}
+			if end == len(value) {
+				return logRecord{}, false
+			}
+			unquoted, err := strconv.Unquote(value[:end+1])
+			if err != nil {
+				return logRecord{}, false
+			}
+			fields = append(fields, logField{Key: key, Value: unquoted})
+			rest = strings.TrimLeft(value[end+1:], " ")
+			continue
+		}
+
+		value, rest, _ = strings.Cut(value, " ")
+		fields = append(fields, logField{Key: key, Value: value})
+		rest = strings.TrimLeft(rest, " ")
+	}
+	return newLogRecord(fields)
+}
+
+// parseConsoleLog reads the framework's console format. Values are not
+// quoted, so each runs up to the next " key=".
+func parseConsoleLog(timestamp, level, rest string) logRecord {
+	record := logRecord{Time: timestamp, Level: normalizeLevel(level), Message: rest}
+
+	message, pairs, ok := strings.Cut(rest, " |")
+	if !ok {
+		return record
+	}
+	record.Message = message
+
+	keys := consoleKeyPattern.FindAllStringSubmatchIndex(pairs, -1)
+	for i, k := range keys {
+		end := len(pairs)
+		if i+1 < len(keys) {
+			end = keys[i+1][0]
+		}
+		record.Fields = append(record.Fields, logField{Key: pairs[k[2]:k[3]], Value: pairs[k[1]:end]})
+	}
+	return record
+}
+
+// newLogRecord picks the time, level and message out of fields. A line
+// with neither a level nor a message is not treated as a log record.
+func newLogRecord(fields []logField) (logRecord, bool) {
+	var record logRecord
+	found := false
+	for _, f := range fields {
+		switch strings.ToLower(f.Key) {
+		case "time", "ts", "timestamp":
+			if record.Time == "" {
+				record.Time = f.Value
+				continue
+			}
+		case "level", "lvl", "severity":
+			if record.Level == "" {
+				if record.Level = normalizeLevel(f.Value); record.Level != "" {
+					found = true
+					continue
+				}
+			}
+		case "msg", "message":
+			if record.Message == "" {
+				record.Message = f.Value
+				found = true
+				continue
+			}
+		}
+		record.Fields = append(record.Fields, f)
+	}
+	return record, found
+}
+
+// stackField returns the index of a field holding a stack trace, or -1
+func (r logRecord) stackField() int {
+	for i, f := range r.Fields {
+		if f.Key == "stack" || goroutinePattern.MatchString(strings.SplitN(f.Value, "\n", 2)[0]) {
+			return i
+		}
+	}
+	return -1
+}
+
+var (
+	logTimeStyle  = lipgloss.NewStyle().Foreground(colors.Muted)
+	logKeyStyle   = lipgloss.NewStyle().Foreground(colors.Muted)
+	logLevelStyle = map[string]lipgloss.Style{
+		levelDebug: lipgloss.NewStyle().Foreground(colors.Muted),
+		levelInfo:  lipgloss.NewStyle().Foreground(colors.Primary),
+		levelWarn:  lipgloss.NewStyle().Foreground(colors.Warning).Bold(true),
+		levelError: lipgloss.NewStyle().Foreground(colors.Error).Bold(true),
+	}
+	logFastStyle = lipgloss.NewStyle().Foreground(colors.Success)
+	logSlowStyle = lipgloss.NewStyle().Foreground(colors.Warning)
+	logVerySlow  = lipgloss.NewStyle().Foreground(colors.Error).Bold(true)
+)
+
+// pretty renders the record on one line. Durations are coloured by how
+// long they took and stack traces are collapsed to the frame that caused
+// them.
+func (r logRecord) pretty() string {
+	var b strings.Builder
+
+	if r.Time != "" {
+		b.WriteString(logTimeStyle.Render(shortLogTime(r.Time)) + " ")
+	}
+	if r.Level != "" {
+		b.WriteString(logLevelStyle[r.Level].Render(fmt.Sprintf("%-5s", strings.ToUpper(r.Level))) + " ")
+	}
+	b.WriteString(r.Message)
+
+	stack := r.stackField()
+	for i, f := range r.Fields {
+		value := f.Value
+		if i == stack && strings.Contains(value, "\n") {
+			value = collapseStack(value)
+		} else if d, ok := durationValue(value); ok {
+			value = durationStyle(d).Render(value)
+		} else if strings.ContainsAny(value, " \t\n\"") || value == "" {
+			value = fmt.Sprintf("%q", value)
+		}
+		b.WriteString(" " + logKeyStyle.Render(f.Key+"=") + value)
+	}
+	return b.String()
+}
+
+// durationValue parses a field value written as a duration. A unit is
+// required, as ParseDuration also accepts a bare 0 such as a zero count.
+func durationValue(value string) (time.Duration, bool) {
+	if !strings.HasSuffix(value, "s") && !strings.HasSuffix(value, "m") && !strings.HasSuffix(value, "h") {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil
+}
+
+// json renders the record as a JSON object with time, level and msg first
+func (r logRecord) json() string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	write := func(key, value string) {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, _ := json.Marshal(value)
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+
+	if r.Time != "" {
+		write("time", r.Time)
+	}
+	if r.Level != "" {
+		write("level", r.Level)
+	}
+	write("msg", r.Message)
+	for _, f := range r.Fields {
+		write(f.Key, f.Value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// shortLogTime trims an RFC 3339 timestamp to the local time of day
+func shortLogTime(value string) string {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t.Local().Format("15:04:05")
+	}
+	return value
+}
+
+// durationStyle colours request durations: green under 100ms, amber
+// under a second and red beyond
+func durationStyle(d time.Duration) lipgloss.Style {
+	switch {
+	case d < 100*time.Millisecond:
+		return logFastStyle
+	case d < time.Second:
+		return logSlowStyle
+	}
+	return logVerySlow
+}
+
+// collapseStack summarises a stack trace as the frame that caused it
+func collapseStack(stack string) string {
+	lines := strings.Split(strings.TrimRight(stack, "\n"), "\n")
+	summary := fmt.Sprintf("[stack trace, %d lines, press s to show]", len(lines))
+	if frame := culpritFrame(lines); frame != "" {
+		summary = frame + " " + summary
+	}
+	return logLevelStyle[levelError].Render(summary)
+}
+
+// culpritFrame returns "function at file:line" for the first frame of
+// app code after the panic, skipping the runtime and the framework
+func culpritFrame(lines []string) string {
+	start := 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, "panic(") {
+			start = i + 1
+		}
+	}
+
+	for i := start; i+1 < len(lines); i++ {
+		fn := lines[i]
+		if !stackFuncPattern.MatchString(fn) || !strings.HasPrefix(lines[i+1], "\t") {
+			continue
+		}
+		if strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "runtime/debug.") ||
+			strings.HasPrefix(fn, "panic(") || strings.HasPrefix(fn, "github.com/velocitykode/velocity/") {
+			continue
+		}
+
+		// The arguments are the last parentheses; receivers such as
+		// (*Server) come earlier and stay part of the name
+		name := fn[:strings.LastIndex(fn, "(")]
+		location := strings.TrimSpace(lines[i+1])
+		if j := strings.LastIndex(location, " +0x"); j > 0 {
+			location = location[:j]
+		}
+		return name + " at " + location
+	}
+	return ""
+}
+
+// isStackLine reports whether line continues a stack trace
+func isStackLine(line string) bool {
+	return strings.HasPrefix(line, "\t") || goroutinePattern.MatchString(line) ||
+		stackFuncPattern.MatchString(line) || strings.HasPrefix(line, "created by ") ||
+		strings.HasPrefix(line, "...")
+}
+
+// stackTraces remembers the last stack trace collapsed in the output so
+// it can be shown in full on request
+type stackTraces struct {
+	mu   sync.Mutex
+	last string
+}
+
+func (s *stackTraces) remember(stack string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = stack
+}
+
+// show prints the last stack trace in full
+func (s *stackTraces) show() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == "" {
+		ui.Muted("No stack trace to show")
+		return
+	}
+	fmt.Fprintln(os.Stdout, strings.TrimRight(s.last, "\n"))
+}
+
+// Log levels a line can be recognised as
+const (
+	levelDebug = "debug"
+	levelInfo  = "info"
+	levelWarn  = "warn"
+	levelError = "error"
+)
+
+var (
+	// level=debug, lvl=info, "level":"warn", severity: error
+	levelKeyPattern = regexp.MustCompile(`(?i)\b(?:level|lvl|severity)"?\s*[=:]\s*"?([a-z]+)`)
+	// DEBUG, [INFO], WARN: written in capitals by most plain loggers
+	levelWordPattern = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|PANIC)\b`)
+)
+
+// lineLevel returns the log level of a line, or "" when it has none
+func lineLevel(line string) string {
+	if m := levelKeyPattern.FindStringSubmatch(line); m != nil {
+		return normalizeLevel(m[1])
+	}
+	if m := levelWordPattern.FindStringSubmatch(line); m != nil {
+		return normalizeLevel(m[1])
+	}
+	return ""
+}
+
+// normalizeLevel maps a level name to one of the level constants, or ""
+// when it is not one
+func normalizeLevel(word string) string {
+	switch strings.ToLower(word) {
+	case "trace", "debug":
+		return levelDebug
+	case "info":
+		return levelInfo
+	case "warn", "warning":
+		return levelWarn
+	case "error", "fatal", "panic":
+		return levelError
+	}
+	return ""
+}
+
+// logFilter hides debug and info lines from the app while it is on.
+// Lines without a recognisable level, such as stack traces, always show.
+type logFilter struct {
+	on atomic.Bool
+}
+
+// toggle switches the filter and reports whether it is now on
+func (f *logFilter) toggle() bool {
+	on := !f.on.Load()
+	f.on.Store(on)
+	return on
+}
+
+// logWriter formats the app's output line by line. Lines it does not
+// recognise pass through as written; stack traces are gathered into the
+// record that introduced them, or into one of their own.
+type logWriter struct {
+	out    io.Writer
+	format string
+	filter *logFilter
+	stacks *stackTraces
+
+	mu      sync.Mutex
+	buf     []byte
+	pending *logRecord // Record whose stack trace is still arriving
+	stack   int        // Index of the stack field in pending
+	idle    *time.Timer
+}
+
+// newLogWriter returns a writer that formats lines for out. filter and
+// stacks may be nil.
+func newLogWriter(out io.Writer, format string, filter *logFilter, stacks *stackTraces) io.Writer {
+	return &logWriter{out: out, format: format, filter: filter, stacks: stacks}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf[:i])
+		w.buf = w.buf[i+1:]
+
+		if err := w.line(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes a final line the app left without a newline and a stack
+// trace still being gathered. It is called once the app has exited.
+func (w *logWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		line := string(w.buf)
+		w.buf = nil
+		if err := w.line(line); err != nil {
+			return err
+		}
+	}
+	return w.flushPending()
+}
+
+// line handles one complete line; the caller holds mu
+func (w *logWriter) line(line string) error {
+	if w.format == logFormatRaw {
+		return w.emit(line, lineLevel(line))
+	}
+
+	if w.pending != nil {
+		if line != "" && isStackLine(line) {
+			w.pending.Fields[w.stack].Value += "\n" + line
+			w.idle.Reset(stackIdleTime)
+			return nil
+		}
+		if err := w.flushPending(); err != nil {
+			return err
+		}
+		if line == "" {
+			return nil // The blank line that ends the trace
+		}
+	}
+
+	record, ok := parseLogLine(line)
+	if !ok && goroutinePattern.MatchString(line) {
+		record, ok = logRecord{Fields: []logField{{Key: "stack", Value: line}}}, true
+	}
+	if !ok {
+		return w.emit(line, lineLevel(line))
+	}
+
+	// A stack trace may follow on the lines after its first
+	if i := record.stackField(); i >= 0 && goroutinePattern.MatchString(record.Fields[i].Value) {
+		w.pending, w.stack = &record, i
+		if w.idle == nil {
+			w.idle = time.AfterFunc(stackIdleTime, w.flushIdle)
+		} else {
+			w.idle.Reset(stackIdleTime)
+		}
+		return nil
+	}
+	return w.record(record)
+}
+
+// flushIdle writes a stack trace the app has stopped adding to
+func (w *logWriter) flushIdle() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushPending()
+}
+
+func (w *logWriter) flushPending() error {
+	if w.pending == nil {
+		return nil
+	}
+	record := *w.pending
+	w.pending = nil
+	w.idle.Stop()
+	return w.record(record)
+}
+
+// record writes a structured record in the chosen format
+func (w *logWriter) record(r logRecord) error {
+	if i := r.stackField(); i >= 0 && w.stacks != nil {
+		w.stacks.remember(r.Fields[i].Value)
+	}
+
+	if w.format == logFormatJSON {
+		return w.emit(r.json(), r.Level)
+	}
+
+	// A stack trace on its own is shown as just its summary
+	if r.Time == "" && r.Level == "" && r.Message == "" && len(r.Fields) == 1 && r.stackField() == 0 {
+		return w.emit(collapseStack(r.Fields[0].Value), "")
+	}
+	return w.emit(r.pretty(), r.Level)
+}
+
+// emit writes a line unless the filter hides its level
+func (w *logWriter) emit(line, level string) error {
+	if w.filter != nil && w.filter.on.Load() && (level == levelDebug || level == levelInfo) {
+		return nil
+	}
+	_, err := io.WriteString(w.out, line+"\n")
+	return err
+}
@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		ok     bool
+		want   logRecord
+		fields string
+	}{
+		{
+			name: "json",
+			line: `{"time":"2024-01-01T10:00:00Z","level":"INFO","msg":"Server starting","port":4000}`,
+			ok:   true,
+			want: logRecord{Time: "2024-01-01T10:00:00Z", Level: levelInfo, Message: "Server starting"}, fields: "port=4000",
+		},
+		{
+			name: "logfmt",
+			line: `time=2024-01-01T10:00:00Z level=WARN msg="slow query" table=users duration=1.5s`,
+			ok:   true,
+			want: logRecord{Time: "2024-01-01T10:00:00Z", Level: levelWarn, Message: "slow query"}, fields: "table=users duration=1.5s",
+		},
+		{
+			name: "console",
+			line: `[15:04:05] INFO: HTTP Request | method=GET path=/users status=200 duration=1.2ms`,
+			ok:   true,
+			want: logRecord{Time: "15:04:05", Level: levelInfo, Message: "HTTP Request"}, fields: "method=GET path=/users status=200 duration=1.2ms",
+		},
+		{
+			name: "console values with spaces",
+			line: `[15:04:05] ERROR: Server failed to start | error=listen tcp :4000: bind: address in use`,
+			ok:   true,
+			want: logRecord{Time: "15:04:05", Level: levelError, Message: "Server failed to start"}, fields: "error=listen tcp :4000: bind: address in use",
+		},
+		{
+			name: "console without fields",
+			line: `[15:04:05] INFO: Application started`,
+			ok:   true,
+			want: logRecord{Time: "15:04:05", Level: levelInfo, Message: "Application started"},
+		},
+		{name: "plain text", line: "Listening on port=4000"},
+		{name: "pairs without level or message", line: "a=1 b=2"},
+		{name: "broken json", line: `{"level":"info"`},
+		{name: "unterminated quote", line: `level=info msg="oops`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseLogLine(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("parseLogLine() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got.Time != tt.want.Time || got.Level != tt.want.Level || got.Message != tt.want.Message {
+				t.Errorf("parseLogLine() = %+v, want %+v", got, tt.want)
+			}
+
+			var fields []string
+			for _, f := range got.Fields {
+				fields = append(fields, f.Key+"="+f.Value)
+			}
+			if strings.Join(fields, " ") != tt.fields {
+				t.Errorf("fields = %q, want %q", strings.Join(fields, " "), tt.fields)
+			}
+		})
+	}
+}
+
+func TestLogRecord_Pretty(t *testing.T) {
+	record := logRecord{
+		Time:    "15:04:05",
+		Level:   levelWarn,
+		Message: "slow request",
+		Fields:  []logField{{Key: "path", Value: "/a b"}, {Key: "duration", Value: "1.5s"}},
+	}
+
+	want := `15:04:05 WARN  slow request path="/a b" duration=1.5s`
+	if got := record.pretty(); got != want {
+		t.Errorf("pretty() = %q, want %q", got, want)
+	}
+}
+
+func TestLogRecord_JSON(t *testing.T) {
+	record := logRecord{
+		Time:    "15:04:05",
+		Level:   levelInfo,
+		Message: "HTTP Request",
+		Fields:  []logField{{Key: "status", Value: "200"}, {Key: "path", Value: `/"x"`}},
+	}
+
+	want := `{"time":"15:04:05","level":"info","msg":"HTTP Request","status":"200","path":"/\"x\""}`
+	if got := record.json(); got != want {
+		t.Errorf("json() = %s, want %s", got, want)
+	}
+}
+
+func TestDurationStyle(t *testing.T) {
+	if durationStyle(5*time.Millisecond).GetForeground() != logFastStyle.GetForeground() {
+		t.Error("fast durations should use the fast style")
+	}
+	if durationStyle(300*time.Millisecond).GetForeground() != logSlowStyle.GetForeground() {
+		t.Error("slow durations should use the slow style")
+	}
+	if durationStyle(2*time.Second).GetForeground() != logVerySlow.GetForeground() {
+		t.Error("very slow durations should use the very slow style")
+	}
+}
+
+const recoveredStack = `goroutine 7 [running]:
+runtime/debug.Stack()
+	/usr/local/go/src/runtime/debug/stack.go:26 +0x5e
+myapp/app/middleware.RecoveryMiddleware.func1.1()
+	/app/app/middleware/middleware.go:40 +0x2b
+panic({0x6683f8?, 0x53f030?})
+	/usr/local/go/src/runtime/panic.go:859 +0x125
+myapp/app/controllers.(*UserController).Show(...)
+	/app/app/controllers/user.go:12
+net/http.HandlerFunc.ServeHTTP(0xc000010000?, {0x7a1b20?, 0xc0000b2000?}, 0xc0000a4000?)
+	/usr/local/go/src/net/http/server.go:2220 +0x29
+created by net/http.(*Server).Serve in goroutine 1
+	/usr/local/go/src/net/http/server.go:3454 +0x485`
+
+func TestCulpritFrame(t *testing.T) {
+	got := culpritFrame(strings.Split(recoveredStack, "\n"))
+	want := "myapp/app/controllers.(*UserController).Show at /app/app/controllers/user.go:12"
+	if got != want {
+		t.Errorf("culpritFrame() = %q, want %q", got, want)
+	}
+
+	if got := culpritFrame([]string{"goroutine 1 [running]:"}); got != "" {
+		t.Errorf("culpritFrame() without frames = %q, want empty", got)
+	}
+}
+
+func TestLineLevel(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{`time=2024-01-01T00:00:00Z level=DEBUG msg="cache miss"`, levelDebug},
+		{`{"time":"2024-01-01","level":"info","msg":"request"}`, levelInfo},
+		{`lvl=warn msg=slow`, levelWarn},
+		{`2024/01/01 12:00:00 [ERROR] database unavailable`, levelError},
+		{`WARNING: disk almost full`, levelWarn},
+		{`TRACE entering handler`, levelDebug},
+		{`panic: runtime error`, ""},
+		{`FATAL could not bind`, levelError},
+		{`goroutine 1 [running]:`, ""},
+		{`an error occurred in lowercase`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := lineLevel(tt.line); got != tt.want {
+			t.Errorf("lineLevel(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	var out bytes.Buffer
+	filter := &logFilter{}
+	w := newLogWriter(&out, logFormatRaw, filter, nil)
+
+	logs := "level=debug msg=a\nlevel=info msg=b\nlevel=warn msg=c\nlevel=error msg=d\n\tat main.go:10\n"
+
+	w.Write([]byte(logs))
+	if out.String() != logs {
+		t.Errorf("unfiltered output = %q, want every line", out.String())
+	}
+
+	if !filter.toggle() {
+		t.Fatal("toggle() should turn the filter on")
+	}
+	out.Reset()
+
+	// Lines split across writes are judged once complete
+	w.Write([]byte(logs[:10]))
+	w.Write([]byte(logs[10:]))
+	want := "level=warn msg=c\nlevel=error msg=d\n\tat main.go:10\n"
+	if out.String() != want {
+		t.Errorf("filtered output = %q, want %q", out.String(), want)
+	}
+
+	if filter.toggle() {
+		t.Error("toggle() should turn the filter back off")
+	}
+}
+
+func TestLogWriter_CollapsesStackTraces(t *testing.T) {
+	var out bytes.Buffer
+	var stacks stackTraces
+	w := newLogWriter(&out, logFormatPretty, nil, &stacks)
+
+	// The framework's console logger writes the stack unquoted, so it
+	// arrives on the lines after the record
+	w.Write([]byte("[15:04:05] ERROR: Panic recovered | error=boom stack=" + recoveredStack + "\n\n"))
+	w.Write([]byte("[15:04:06] INFO: Still serving\n"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("output = %q, want the record and the next line only", out.String())
+	}
+	if !strings.Contains(lines[0], "stack=myapp/app/controllers.(*UserController).Show at /app/app/controllers/user.go:12") ||
+		!strings.Contains(lines[0], "13 lines, press s to show") {
+		t.Errorf("collapsed record = %q", lines[0])
+	}
+	if lines[1] != "15:04:06 INFO  Still serving" {
+		t.Errorf("next line = %q", lines[1])
+	}
+	if stacks.last != recoveredStack {
+		t.Errorf("remembered stack = %q, want the full trace", stacks.last)
+	}
+}
+
+func TestLogWriter_PanicFlushedWhenIdle(t *testing.T) {
+	var out syncBuffer
+	w := newLogWriter(&out, logFormatJSON, nil, nil)
+
+	w.Write([]byte("panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x18\n"))
+
+	// Nothing follows the trace, so it is written once the app goes quiet
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), `"stack"`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("stack trace never flushed, output = %q", out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	want := "panic: boom\n\n" + `{"msg":"","stack":"goroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x18"}` + "\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestLogWriter_FilterUsesRecordLevel(t *testing.T) {
+	var out bytes.Buffer
+	filter := &logFilter{}
+	filter.toggle()
+	w := newLogWriter(&out, logFormatPretty, filter, nil)
+
+	w.Write([]byte("[15:04:05] INFO: hidden\n[15:04:05] WARN: shown\nplain line\n"))
+
+	if want := "15:04:05 WARN  shown\nplain line\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestLogWriter_Raw(t *testing.T) {
+	var out bytes.Buffer
+	w := newLogWriter(&out, logFormatRaw, nil, nil)
+
+	logs := `{"level":"info","msg":"untouched"}` + "\n[15:04:05] INFO: untouched\n"
+	w.Write([]byte(logs))
+	if out.String() != logs {
+		t.Errorf("raw output = %q, want %q", out.String(), logs)
+	}
+}
+
+func TestDurationValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"1.5s", true},
+		{"250ms", true},
+		{"3µs", true},
+		{"2h", true},
+		{"0s", true},
+		{"0", false},
+		{"12", false},
+		{"items", false},
+	}
+
+	for _, tt := range tests {
+		if _, got := durationValue(tt.value); got != tt.want {
+			t.Errorf("durationValue(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLogWriter_FlushWritesPartialLine(t *testing.T) {
+	var out bytes.Buffer
+	w := newLogWriter(&out, logFormatPretty, nil, nil)
+
+	w.Write([]byte("first\nexiting without a newline"))
+	if out.String() != "first\n" {
+		t.Fatalf("output before Flush = %q, want only the complete line", out.String())
+	}
+
+	if err := w.(flusher).Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if want := "first\nexiting without a newline\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestStartProcess_FlushesOutput(t *testing.T) {
+	var out syncBuffer
+	cmd := exec.Command("printf", "last words")
+	cmd.Stdout = newLogWriter(&out, logFormatPretty, nil, nil)
+	p, err := startProcess(cmd)
+	if err != nil {
+		t.Skipf("printf not available: %v", err)
+	}
+
+	<-p.exited
+	if want := "last words\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestValidateLogFormat(t *testing.T) {
+	for _, format := range []string{logFormatRaw, logFormatPretty, logFormatJSON} {
+		if err := validateLogFormat(format); err != nil {
+			t.Errorf("validateLogFormat(%q) error = %v", format, err)
+		}
+	}
+	if err := validateLogFormat("xml"); err == nil {
+		t.Error("validateLogFormat(xml) should error")
+	}
+}
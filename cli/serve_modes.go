@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// Files and directories used by the --cover and --pprof modes
+const (
+	coverageDir    = ".velocity/coverage"
+	profilesDir    = ".velocity/profiles"
+	pprofOverlay   = ".velocity/tmp/overlay.json"
+	pprofSource    = ".velocity/tmp/velocity_pprof.go"
+	pprofAddrEnv   = "VELOCITY_PPROF_ADDR"
+	cpuProfileTime = 30 * time.Second
+)
+
+// pprofProgram is compiled into the app's main package with --pprof. It
+// serves the pprof handlers on a private address, so the app's own code
+// and routes are left alone.
+const pprofProgram = `// Code generated by velocity serve --pprof. DO NOT EDIT.
+
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"os"
+)
+
+func init() {
+	addr := os.Getenv("` + pprofAddrEnv + `")
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	go http.ListenAndServe(addr, mux)
+}
+`
+
+// serveModeBuildArgs returns the go build flags for --race, --cover and
+// --pprof
+func serveModeBuildArgs() []string {
+	var args []string
+	if serveRace {
+		args = append(args, "-race")
+	}
+	if serveCover {
+		args = append(args, "-cover")
+	}
+	if servePprof {
+		args = append(args, "-overlay", pprofOverlay)
+	}
+	return args
+}
+
+// writePprofOverlay writes the pprof program and a build overlay that
+// adds it to the main package in the current directory
+func writePprofOverlay() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	source, err := filepath.Abs(pprofSource)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(source), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(source, []byte(pprofProgram), 0644); err != nil {
+		return err
+	}
+
+	overlay := map[string]map[string]string{
+		"Replace": {filepath.Join(cwd, "zz_velocity_pprof.go"): source},
+	}
+	data, err := json.MarshalIndent(overlay, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(pprofOverlay, data, 0644)
+}
+
+// prepareServeModes sets up --cover and --pprof and returns the
+// environment the app needs for them
+func prepareServeModes() ([]string, error) {
+	var env []string
+
+	if serveCover {
+		dir, err := filepath.Abs(coverageDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		env = append(env, "GOCOVERDIR="+dir)
+		ui.Info(fmt.Sprintf("Coverage data is written to %s when the app exits", coverageDir))
+	}
+
+	if servePprof {
+		if err := writePprofOverlay(); err != nil {
+			return nil, fmt.Errorf("failed to set up pprof: %w", err)
+		}
+		port, err := freePort()
+		if err != nil {
+			return nil, err
+		}
+		addr := "127.0.0.1:" + port
+		env = append(env, pprofAddrEnv+"="+addr)
+		ui.Info(fmt.Sprintf("Profiling on %s", ui.Highlight("http://"+addr+"/debug/pprof/")))
+		ui.Muted("Press p for a 30s CPU profile, h for a heap profile")
+	}
+
+	return env, nil
+}
+
+// reportCoverage explains how to read the coverage data after serve
+func reportCoverage() {
+	if serveCover {
+		ui.Muted("Coverage report: " + ui.Command("go tool covdata percent -i="+coverageDir))
+	}
+}
+
+// profileKeys binds p and h to profile captures when --pprof set up a
+// pprof address in modeEnv
+func profileKeys(modeEnv []string) []keyBinding {
+	for _, entry := range modeEnv {
+		if addr, ok := strings.CutPrefix(entry, pprofAddrEnv+"="); ok {
+			p := &profiler{addr: addr, dir: profilesDir}
+			return []keyBinding{
+				{key: "p", help: "cpu profile", run: func() { p.capture("cpu") }},
+				{key: "h", help: "heap profile", run: func() { p.capture("heap") }},
+			}
+		}
+	}
+	return nil
+}
+
+// profiler captures profiles from the app's pprof server, one at a time
+type profiler struct {
+	addr string
+	dir  string
+
+	mu      sync.Mutex
+	running bool
+}
+
+// capture fetches a profile in the background and saves it to the
+// profiles directory. kind is "cpu" or "heap".
+func (p *profiler) capture(kind string) {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		ui.Warning("A profile is already being captured")
+		return
+	}
+	p.running = true
+	p.mu.Unlock()
+
+	url := "http://" + p.addr + "/debug/pprof/heap"
+	timeout := 10 * time.Second
+	if kind == "cpu" {
+		url = fmt.Sprintf("http://%s/debug/pprof/profile?seconds=%d", p.addr, int(cpuProfileTime.Seconds()))
+		timeout += cpuProfileTime
+		ui.Info(fmt.Sprintf("Capturing a %s CPU profile...", cpuProfileTime))
+	}
+
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			p.running = false
+			p.mu.Unlock()
+		}()
+
+		path, err := p.save(kind, url, timeout)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Profile failed: %v", err))
+			return
+		}
+		ui.Success(fmt.Sprintf("Saved %s profile: %s", kind, path))
+		ui.Muted("View with: " + ui.Command("go tool pprof -http=: "+path))
+	}()
+}
+
+// save downloads a profile from url into the profiles directory
+func (p *profiler) save(kind, url string, timeout time.Duration) (string, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pprof returned %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(p.dir, fmt.Sprintf("%s-%s.pprof", kind, time.Now().Format("20060102-150405")))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
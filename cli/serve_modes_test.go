@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeModeBuildArgs(t *testing.T) {
+	defer func() { serveRace, serveCover, servePprof = false, false, false }()
+
+	serveRace, serveCover, servePprof = false, false, false
+	if got := serveModeBuildArgs(); len(got) != 0 {
+		t.Errorf("serveModeBuildArgs() = %v, want none", got)
+	}
+
+	serveRace, serveCover, servePprof = true, true, true
+	want := []string{"-race", "-cover", "-overlay", pprofOverlay}
+	if got := serveModeBuildArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("serveModeBuildArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestPrepareServeModes_Cover(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	defer func() { serveCover = false }()
+
+	serveCover = true
+	env, err := prepareServeModes()
+	if err != nil {
+		t.Fatalf("prepareServeModes() error = %v", err)
+	}
+
+	dir, _ := filepath.Abs(coverageDir)
+	if !reflect.DeepEqual(env, []string{"GOCOVERDIR=" + dir}) {
+		t.Errorf("env = %v, want GOCOVERDIR=%s", env, dir)
+	}
+	if _, err := os.Stat(coverageDir); err != nil {
+		t.Error("coverage directory should be created")
+	}
+}
+
+func TestPprofOverlay_ServesProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	defer func() { servePprof = false }()
+
+	// The app has no pprof code of its own
+	os.WriteFile("go.mod", []byte("module testserve\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nimport \"time\"\n\nfunc main() { time.Sleep(time.Minute) }\n"), 0644)
+
+	servePprof = true
+	env, err := prepareServeModes()
+	if err != nil {
+		t.Fatalf("prepareServeModes() error = %v", err)
+	}
+	addr := strings.TrimPrefix(env[0], pprofAddrEnv+"=")
+
+	if output, err := exec.Command("go", serveBuildArgs("app")...).CombinedOutput(); err != nil {
+		t.Fatalf("build with overlay failed: %v\n%s", err, output)
+	}
+	if _, err := os.Stat("zz_velocity_pprof.go"); !os.IsNotExist(err) {
+		t.Error("the pprof file should only exist in the overlay")
+	}
+
+	app := exec.Command("./app")
+	app.Env = append(os.Environ(), env...)
+	if err := app.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Process.Kill()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get("http://" + addr + "/debug/pprof/")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("pprof index status = %d", resp.StatusCode)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pprof server never came up: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	p := &profiler{addr: addr, dir: profilesDir}
+	path, err := p.save("heap", "http://"+addr+"/debug/pprof/heap", 5*time.Second)
+	if err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if !strings.HasPrefix(path, filepath.Join(profilesDir, "heap-")) {
+		t.Errorf("profile saved to %s", path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Error("heap profile should be written")
+	}
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+// Defaults for serve settings left out of velocity.yaml
+const (
+	defaultGracePeriod  = 5 * time.Second
+	defaultReadyTimeout = 10 * time.Second
+	portFreeTimeout     = 5 * time.Second
+)
+
+// serverProcess is a running server binary or supervised process. It
+// runs in its own process group so stopping it also stops anything it
+// started, such as the node processes behind a frontend dev server.
+type serverProcess struct {
+	cmd    *exec.Cmd
+	exited chan struct{}
+	err    error
+}
+
+// flusher is an output writer holding back a partial line
+type flusher interface {
+	Flush() error
+}
+
+// startProcess starts cmd and reaps it in the background. Once it exits,
+// its output is flushed.
+func startProcess(cmd *exec.Cmd) (*serverProcess, error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &serverProcess{cmd: cmd, exited: make(chan struct{})}
+	go func() {
+		p.err = cmd.Wait()
+		flushOutput(cmd)
+		close(p.exited)
+	}()
+	return p, nil
+}
+
+// flushOutput flushes the output writers of an exited cmd that buffer
+// lines, so a final partial line or stack trace is not lost
+func flushOutput(cmd *exec.Cmd) {
+	for _, out := range []any{cmd.Stdout, cmd.Stderr} {
+		if f, ok := out.(flusher); ok {
+			f.Flush()
+		}
+	}
+}
+
+// stop asks the process to exit with SIGTERM and kills it if it is still
+// running after grace. It reports whether the process had to be killed.
+func (p *serverProcess) stop(grace time.Duration) (killed bool) {
+	select {
+	case <-p.exited:
+		return false
+	default:
+	}
+
+	if err := p.signal(syscall.SIGTERM); err != nil {
+		p.signal(syscall.SIGKILL)
+		<-p.exited
+		return false
+	}
+
+	select {
+	case <-p.exited:
+		// Children may outlive the leader; make sure the group is gone
+		p.signal(syscall.SIGKILL)
+		return false
+	case <-time.After(grace):
+		p.signal(syscall.SIGKILL)
+		<-p.exited
+		return true
+	}
+}
+
+// signal sends sig to the process group, or to the process alone when
+// the group cannot be signalled
+func (p *serverProcess) signal(sig syscall.Signal) error {
+	if err := syscall.Kill(-p.cmd.Process.Pid, sig); err == nil {
+		return nil
+	}
+	return p.cmd.Process.Signal(sig)
+}
+
+// waitPortFree waits until nothing is listening on port
+func waitPortFree(port string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ln, err := net.Listen("tcp", ":"+port)
+		if err == nil {
+			return ln.Close()
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("port %s is still in use", port)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// waitReady waits until the server answers on port. With a health path
+// the server must return a 2xx or 3xx for it; without one an accepted
+// connection is enough. It gives up early if the process exits.
+func (p *serverProcess) waitReady(port, healthPath string, timeout time.Duration) error {
+	client := &http.Client{
+		Timeout: time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	address := net.JoinHostPort("127.0.0.1", port)
+
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case <-p.exited:
+			return fmt.Errorf("server exited before it was ready")
+		default:
+		}
+
+		if healthPath == "" {
+			if conn, err := net.DialTimeout("tcp", address, time.Second); err == nil {
+				return conn.Close()
+			}
+		} else if resp, err := client.Get("http://" + address + healthPath); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 400 {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("server not ready after %s", timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// serveTimings returns the grace period and ready timeout from cfg, with
+// defaults for the ones not set
+func serveTimings(cfg config.ServeConfig) (grace, ready time.Duration) {
+	grace, ready = cfg.GracePeriod, cfg.ReadyTimeout
+	if grace == 0 {
+		grace = defaultGracePeriod
+	}
+	if ready == 0 {
+		ready = defaultReadyTimeout
+	}
+	return grace, ready
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+func TestServerProcess_StopGraceful(t *testing.T) {
+	p, err := startProcess(exec.Command("sleep", "10"))
+	if err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+
+	start := time.Now()
+	if killed := p.stop(5 * time.Second); killed {
+		t.Error("stop() killed a process that exits on SIGTERM")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stop() took %s, want an immediate exit", elapsed)
+	}
+}
+
+func TestServerProcess_StopEscalatesToKill(t *testing.T) {
+	p, err := startProcess(exec.Command("sh", "-c", `trap "" TERM; while true; do sleep 0.1; done`))
+	if err != nil {
+		t.Skipf("sh not available: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond) // Let the trap be installed
+
+	if killed := p.stop(200 * time.Millisecond); !killed {
+		t.Error("stop() should kill a process that ignores SIGTERM")
+	}
+	select {
+	case <-p.exited:
+	default:
+		t.Error("process should have exited")
+	}
+}
+
+func TestWaitPortFree(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	if err := waitPortFree(port, 200*time.Millisecond); err == nil {
+		t.Error("waitPortFree() should fail while the port is in use")
+	}
+
+	ln.Close()
+	if err := waitPortFree(port, time.Second); err != nil {
+		t.Errorf("waitPortFree() error = %v after the port was freed", err)
+	}
+}
+
+func TestServerProcess_WaitReady(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" || calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	p, err := startProcess(exec.Command("sleep", "10"))
+	if err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	defer p.stop(time.Second)
+
+	if err := p.waitReady(port, "/health", 5*time.Second); err != nil {
+		t.Fatalf("waitReady() error = %v", err)
+	}
+	if calls.Load() < 3 {
+		t.Errorf("health endpoint polled %d times, want it polled until healthy", calls.Load())
+	}
+
+	if err := p.waitReady(port, "", time.Second); err != nil {
+		t.Errorf("waitReady() without health path error = %v", err)
+	}
+}
+
+func TestServerProcess_WaitReadyExited(t *testing.T) {
+	p, err := startProcess(exec.Command("true"))
+	if err != nil {
+		t.Skipf("true not available: %v", err)
+	}
+
+	if err := p.waitReady("1", "", 5*time.Second); err == nil {
+		t.Error("waitReady() should fail once the process exits")
+	}
+}
+
+func TestServeTimings(t *testing.T) {
+	grace, ready := serveTimings(config.ServeConfig{})
+	if grace != defaultGracePeriod || ready != defaultReadyTimeout {
+		t.Errorf("serveTimings() = %s, %s, want defaults", grace, ready)
+	}
+
+	grace, ready = serveTimings(config.ServeConfig{GracePeriod: time.Second, ReadyTimeout: 2 * time.Second})
+	if grace != time.Second || ready != 2*time.Second {
+		t.Errorf("serveTimings() = %s, %s, want 1s, 2s", grace, ready)
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveReloadPath is the event stream browsers listen on for reloads
+const liveReloadPath = "/__velocity/livereload"
+
+// liveReloadScript is injected into HTML pages served through the proxy
+const liveReloadScript = `<script>new EventSource("` + liveReloadPath + `").addEventListener("reload", function () { location.reload() })</script>`
+
+// devProxy sits on the public port in front of the app. Requests that
+// arrive while the app restarts are held until it is ready again, and
+// HTML responses get a script that reloads the page after a rebuild.
+type devProxy struct {
+	proxy       *httputil.ReverseProxy
+	holdTimeout time.Duration
+
+	mu      sync.Mutex
+	ready   chan struct{}
+	clients map[chan struct{}]bool
+}
+
+// newDevProxy returns a proxy to the app at target, initially holding
+// requests until Resume is called
+func newDevProxy(target *url.URL, holdTimeout time.Duration) *devProxy {
+	p := &devProxy{
+		holdTimeout: holdTimeout,
+		ready:       make(chan struct{}),
+		clients:     make(map[chan struct{}]bool),
+	}
+
+	p.proxy = httputil.NewSingleHostReverseProxy(target)
+	director := p.proxy.Director
+	p.proxy.Director = func(r *http.Request) {
+		director(r)
+		// Without the browser's Accept-Encoding the transport decompresses
+		// responses itself, so the reload script can be injected
+		r.Header.Del("Accept-Encoding")
+	}
+	p.proxy.ModifyResponse = injectLiveReload
+	p.proxy.FlushInterval = -1
+	p.proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, fmt.Sprintf("velocity serve: app is not responding: %v", err), http.StatusBadGateway)
+	}
+	return p
+}
+
+// Pause holds new requests until Resume
+func (p *devProxy) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.ready:
+		p.ready = make(chan struct{})
+	default:
+	}
+}
+
+// Resume releases held requests to the app
+func (p *devProxy) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.ready:
+	default:
+		close(p.ready)
+	}
+}
+
+// Reload tells every connected browser to reload
+func (p *devProxy) Reload() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for client := range p.clients {
+		select {
+		case client <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (p *devProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == liveReloadPath {
+		p.serveEvents(w, r)
+		return
+	}
+
+	p.mu.Lock()
+	ready := p.ready
+	p.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-r.Context().Done():
+		return
+	case <-time.After(p.holdTimeout):
+		http.Error(w, "velocity serve: app did not become ready in time", http.StatusServiceUnavailable)
+		return
+	}
+
+	p.proxy.ServeHTTP(w, r)
+}
+
+// serveEvents streams reload events to a browser
+func (p *devProxy) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan struct{}, 1)
+	p.mu.Lock()
+	p.clients[client] = true
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.clients, client)
+		p.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-client:
+			fmt.Fprint(w, "event: reload\ndata: reload\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// injectLiveReload adds the reload script to HTML responses, before
+// </body> when there is one
+func injectLiveReload(resp *http.Response) error {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); i >= 0 {
+		body = append(body[:i], append([]byte(liveReloadScript), body[i:]...)...)
+	} else {
+		body = append(body, liveReloadScript...)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// freePort returns a port nothing is listening on
+func freePort() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer ln.Close()
+
+	_, port, err := net.SplitHostPort(ln.Addr().String())
+	return port, err
+}
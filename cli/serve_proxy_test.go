@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestProxy(t *testing.T, handler http.HandlerFunc) (*devProxy, *httptest.Server) {
+	t.Helper()
+	app := httptest.NewServer(handler)
+	t.Cleanup(app.Close)
+
+	target, _ := url.Parse(app.URL)
+	p := newDevProxy(target, 5*time.Second)
+	front := httptest.NewServer(p)
+	t.Cleanup(front.Close)
+	return p, front
+}
+
+func TestDevProxy_HoldsRequestsUntilResume(t *testing.T) {
+	p, front := newTestProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+
+	done := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(front.URL + "/")
+		if err != nil {
+			done <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		done <- string(body)
+	}()
+
+	select {
+	case got := <-done:
+		t.Fatalf("request finished before Resume: %q", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	p.Resume()
+	select {
+	case got := <-done:
+		if got != "ok" {
+			t.Errorf("response = %q, want %q", got, "ok")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("request still held after Resume")
+	}
+
+	// Pause and Resume are idempotent
+	p.Pause()
+	p.Pause()
+	p.Resume()
+	p.Resume()
+	resp, err := http.Get(front.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestDevProxy_HoldTimeout(t *testing.T) {
+	app := httptest.NewServer(http.NotFoundHandler())
+	defer app.Close()
+	target, _ := url.Parse(app.URL)
+
+	front := httptest.NewServer(newDevProxy(target, 100*time.Millisecond))
+	defer front.Close()
+
+	resp, err := http.Get(front.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+}
+
+func TestDevProxy_InjectsLiveReload(t *testing.T) {
+	p, front := newTestProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"ok":true}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><body><h1>Hi</h1></BODY></html>")
+	})
+	p.Resume()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "<html><body><h1>Hi</h1>" + liveReloadScript + "</BODY></html>"},
+		{"/api", `{"ok":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(front.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+			if resp.ContentLength != int64(len(tt.want)) {
+				t.Errorf("Content-Length = %d, want %d", resp.ContentLength, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestDevProxy_ReloadEvent(t *testing.T) {
+	p, front := newTestProxy(t, http.NotFound)
+
+	resp, err := http.Get(front.URL + liveReloadPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("first line = %q", line)
+	}
+	reader.ReadString('\n')
+
+	p.Reload()
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "event: reload\n" {
+		t.Errorf("event line = %q, want %q", line, "event: reload\n")
+	}
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/velocitykode/velocity-cli/internal/colors"
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+// Restart backoff for supervised processes. A process that stayed up for
+// restartResetAfter is considered healthy and starts the backoff over.
+const (
+	restartBackoffMin = time.Second
+	restartBackoffMax = 30 * time.Second
+	restartResetAfter = 10 * time.Second
+)
+
+// supervisor runs the processes declared in velocity.yaml next to the
+// app, restarting them according to their policy
+type supervisor struct {
+	grace time.Duration
+	env   []string
+
+	mu       sync.Mutex
+	running  map[string]*serverProcess
+	stopping bool
+	stopped  chan struct{}
+	wg       sync.WaitGroup
+}
+
+// newSupervisor returns a supervisor that runs processes with env, or the
+// CLI's own environment when env is nil, and gives them grace to exit
+// when stopping
+func newSupervisor(grace time.Duration, env []string) *supervisor {
+	if env == nil {
+		env = os.Environ()
+	}
+	return &supervisor{
+		grace:   grace,
+		env:     env,
+		running: make(map[string]*serverProcess),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start runs a process, writing its output to out, and keeps it running
+// until Stop
+func (s *supervisor) Start(cfg config.ProcessConfig, out io.Writer) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(cfg, out)
+	}()
+}
+
+// run starts cfg and restarts it as its policy allows
+func (s *supervisor) run(cfg config.ProcessConfig, out io.Writer) {
+	backoff := restartBackoffMin
+	restarts := 0
+
+	for {
+		cmd := exec.Command("sh", "-c", cfg.Command)
+		cmd.Dir = cfg.Dir
+		cmd.Stdout = out
+		cmd.Stderr = out
+		cmd.Env = append([]string{}, s.env...)
+		for key, value := range cfg.Env {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+
+		s.mu.Lock()
+		if s.stopping {
+			s.mu.Unlock()
+			return
+		}
+		p, err := startProcess(cmd)
+		if err != nil {
+			s.mu.Unlock()
+			fmt.Fprintf(out, "failed to start: %v\n", err)
+			return
+		}
+		s.running[cfg.Name] = p
+		s.mu.Unlock()
+
+		started := time.Now()
+		<-p.exited
+
+		s.mu.Lock()
+		delete(s.running, cfg.Name)
+		stopping := s.stopping
+		s.mu.Unlock()
+		if stopping {
+			return
+		}
+
+		if p.err != nil {
+			fmt.Fprintf(out, "exited: %v\n", p.err)
+		} else {
+			fmt.Fprintln(out, "exited")
+		}
+		if !shouldRestart(cfg.Restart, p.err) {
+			return
+		}
+
+		if time.Since(started) >= restartResetAfter {
+			backoff, restarts = restartBackoffMin, 0
+		}
+		restarts++
+		if cfg.MaxRestarts > 0 && restarts > cfg.MaxRestarts {
+			fmt.Fprintf(out, "gave up after %d restarts\n", cfg.MaxRestarts)
+			return
+		}
+
+		fmt.Fprintf(out, "restarting in %s\n", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-s.stopped:
+			return
+		}
+		backoff = min(backoff*2, restartBackoffMax)
+	}
+}
+
+// shouldRestart applies a restart policy to how a process exited
+func shouldRestart(policy string, exitErr error) bool {
+	switch policy {
+	case config.RestartAlways:
+		return true
+	case config.RestartNever:
+		return false
+	default:
+		return exitErr != nil
+	}
+}
+
+// Stop stops every process, giving each the grace period to exit before
+// its process group is killed, and waits for them
+func (s *supervisor) Stop() {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		s.wg.Wait()
+		return
+	}
+	s.stopping = true
+	close(s.stopped)
+	running := make([]*serverProcess, 0, len(s.running))
+	for _, p := range s.running {
+		running = append(running, p)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range running {
+		wg.Add(1)
+		go func(p *serverProcess) {
+			defer wg.Done()
+			p.stop(s.grace)
+		}(p)
+	}
+	wg.Wait()
+	s.wg.Wait()
+}
+
+// defaultProcesses returns the processes serve runs when velocity.yaml
+// declares none: the frontend dev server, if package.json has a dev script
+func defaultProcesses() []config.ProcessConfig {
+	data, err := os.ReadFile("package.json")
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil || pkg.Scripts["dev"] == "" {
+		return nil
+	}
+
+	command := "npm run dev"
+	if _, err := exec.LookPath("bun"); err == nil {
+		command = "bun run dev"
+	}
+	return []config.ProcessConfig{{Name: "vite", Command: command}}
+}
+
+// prefixWriter prefixes every line written to it with a coloured name,
+// so the output of several processes can share a terminal. Partial lines
+// are held until they are complete.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+// newPrefixWriters returns a writer per name, with names padded to the
+// same width and coloured in turn. The writers share a lock so lines from
+// different processes never interleave.
+func newPrefixWriters(out io.Writer, names []string) map[string]io.Writer {
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	mu := &sync.Mutex{}
+	writers := make(map[string]io.Writer, len(names))
+	for i, name := range names {
+		style := lipgloss.NewStyle().Foreground(colors.ProcessColors[i%len(colors.ProcessColors)]).Bold(true)
+		label := name + strings.Repeat(" ", width-len(name))
+		writers[name] = &prefixWriter{mu: mu, out: out, prefix: style.Render(label) + " │ "}
+	}
+	return writers
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(w.out, "%s%s\n", w.prefix, w.buf[:i]); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a final line the process left without a newline
+func (w *prefixWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w.out, "%s%s\n", w.prefix, w.buf)
+	w.buf = nil
+	return err
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writers and readers
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestPrefixWriters(t *testing.T) {
+	var out bytes.Buffer
+	writers := newPrefixWriters(&out, []string{"app", "worker"})
+
+	writers["app"].Write([]byte("listening"))
+	writers["worker"].Write([]byte("job done\nnext job\n"))
+	writers["app"].Write([]byte(" on :4000\n"))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %q", len(lines), out.String())
+	}
+
+	tests := []struct {
+		line   string
+		prefix string
+		text   string
+	}{
+		{lines[0], "worker", "job done"},
+		{lines[1], "worker", "next job"},
+		{lines[2], "app   ", "listening on :4000"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(tt.line, tt.prefix) {
+			t.Errorf("line %q should be labelled %q", tt.line, tt.prefix)
+		}
+		if !strings.HasSuffix(tt.line, " │ "+tt.text) {
+			t.Errorf("line %q should end with %q", tt.line, tt.text)
+		}
+	}
+}
+
+func TestPrefixWriter_FlushWritesPartialLine(t *testing.T) {
+	var out bytes.Buffer
+	w := newPrefixWriters(&out, []string{"vite"})["vite"]
+
+	w.Write([]byte("ready\nexiting without a newline"))
+	if err := w.(flusher).Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], " │ exiting without a newline") {
+		t.Errorf("output = %q, want the partial line labelled", out.String())
+	}
+}
+
+func TestShouldRestart(t *testing.T) {
+	failed := errors.New("exit status 1")
+
+	tests := []struct {
+		policy string
+		err    error
+		want   bool
+	}{
+		{config.RestartAlways, nil, true},
+		{config.RestartAlways, failed, true},
+		{config.RestartOnFailure, nil, false},
+		{config.RestartOnFailure, failed, true},
+		{"", failed, true},
+		{"", nil, false},
+		{config.RestartNever, failed, false},
+	}
+
+	for _, tt := range tests {
+		if got := shouldRestart(tt.policy, tt.err); got != tt.want {
+			t.Errorf("shouldRestart(%q, %v) = %v, want %v", tt.policy, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSupervisor_MaxRestarts(t *testing.T) {
+	var out syncBuffer
+	s := newSupervisor(time.Second, nil)
+	s.Start(config.ProcessConfig{Name: "flaky", Command: "echo started; exit 1", MaxRestarts: 1}, &out)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.Stop()
+		t.Fatal("supervisor should give up after max_restarts")
+	}
+
+	got := out.String()
+	if n := strings.Count(got, "started"); n != 2 {
+		t.Errorf("process ran %d times, want 2:\n%s", n, got)
+	}
+	if !strings.Contains(got, "gave up after 1 restarts") {
+		t.Errorf("output should report giving up:\n%s", got)
+	}
+}
+
+func TestSupervisor_Stop(t *testing.T) {
+	var out syncBuffer
+	s := newSupervisor(time.Second, nil)
+	s.Start(config.ProcessConfig{Name: "worker", Command: "sleep 30", Restart: config.RestartAlways}, &out)
+
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	s.Stop()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Stop() took %s", elapsed)
+	}
+
+	s.mu.Lock()
+	running := len(s.running)
+	s.mu.Unlock()
+	if running != 0 {
+		t.Errorf("%d processes still running after Stop", running)
+	}
+	if strings.Contains(out.String(), "restarting") {
+		t.Errorf("a stopped process should not be restarted:\n%s", out.String())
+	}
+
+	// Stopping twice is safe
+	s.Stop()
+}
+
+func TestDefaultProcesses(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	if got := defaultProcesses(); got != nil {
+		t.Errorf("defaultProcesses() without package.json = %v, want none", got)
+	}
+
+	os.WriteFile("package.json", []byte(`{"scripts": {"build": "vite build"}}`), 0644)
+	if got := defaultProcesses(); got != nil {
+		t.Errorf("defaultProcesses() without a dev script = %v, want none", got)
+	}
+
+	os.WriteFile("package.json", []byte(`{"scripts": {"dev": "vite"}}`), 0644)
+	got := defaultProcesses()
+	if len(got) != 1 || got[0].Name != "vite" || !strings.HasSuffix(got[0].Command, " run dev") {
+		t.Errorf("defaultProcesses() = %+v, want the vite dev server", got)
+	}
+}
@@ -2,9 +2,13 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
 )
 
 func TestServeCmd_FlagDefaults(t *testing.T) {
@@ -16,6 +20,15 @@ func TestServeCmd_FlagDefaults(t *testing.T) {
 		{"env", "development"},
 		{"watch", "true"},
 		{"tags", ""},
+		{"proxy", "false"},
+		{"app-port", ""},
+		{"debug", "false"},
+		{"debug-port", "2345"},
+		{"race", "false"},
+		{"cover", "false"},
+		{"pprof", "false"},
+		{"log-format", "pretty"},
+		{"env-file", "[]"},
 	}
 
 	for _, tt := range tests {
@@ -207,11 +220,11 @@ func TestWatchFiles_SkipsVendorAndVelocity(t *testing.T) {
 	os.WriteFile(".velocity/tmp/server", []byte("binary"), 0755)
 	os.WriteFile("app/handler.go", []byte("package app"), 0644)
 
-	rebuild := make(chan bool, 1)
+	rebuild := make(chan string, 1)
 
 	// Run watchFiles in goroutine, it will setup watchers then block
 	go func() {
-		watchFiles(rebuild)
+		watchFiles(newWatchMatcher(config.WatchConfig{}), rebuild)
 	}()
 
 	// Give it time to setup
@@ -247,6 +260,48 @@ func main() {
 	}
 }
 
+func TestRunServer_ServerPanics_ShowsStackTrace(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("go.mod", []byte("module testserve\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte(`package main
+
+func main() {
+	panic("boom")
+}
+`), 0644)
+
+	servePort = "4000"
+	serveEnv = "test"
+	serveBuildTags = ""
+	serveLogFormat = logFormatJSON
+	defer func() { serveLogFormat = logFormatPretty }()
+
+	r, w, _ := os.Pipe()
+	stderr := os.Stderr
+	os.Stderr = w
+	output := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		output <- string(out)
+	}()
+	err := runServer()
+	os.Stderr = stderr
+	w.Close()
+	out := <-output
+
+	if err == nil {
+		t.Error("runServer() should error when the server panics")
+	}
+	// The app exits straight after the trace, before it could go idle
+	if !strings.Contains(out, "panic: boom") || !strings.Contains(out, `"stack":"goroutine 1 [running]:`) {
+		t.Errorf("stderr = %q, want the panic and its stack trace", out)
+	}
+}
+
 func TestRunServer_BuildSucceeds_ServerSucceeds(t *testing.T) {
 	tmpDir := t.TempDir()
 	originalDir, _ := os.Getwd()
@@ -345,8 +400,8 @@ func TestWatchFiles_WalkError(t *testing.T) {
 	os.Chmod("app/controllers", 0000)
 	defer os.Chmod("app/controllers", 0755)
 
-	rebuild := make(chan bool, 1)
-	err := watchFiles(rebuild)
+	rebuild := make(chan string, 1)
+	err := watchFiles(newWatchMatcher(config.WatchConfig{}), rebuild)
 
 	if err == nil {
 		t.Error("watchFiles() should error when walk fails")
@@ -364,12 +419,12 @@ func TestWatchFiles_SetupSuccess(t *testing.T) {
 	os.WriteFile("main.go", []byte("package main"), 0644)
 	os.WriteFile("app/handler.go", []byte("package app"), 0644)
 
-	rebuild := make(chan bool, 1)
+	rebuild := make(chan string, 1)
 
 	// Run watchFiles in goroutine - it will block after setup
 	done := make(chan error, 1)
 	go func() {
-		done <- watchFiles(rebuild)
+		done <- watchFiles(newWatchMatcher(config.WatchConfig{}), rebuild)
 	}()
 
 	// Give it time to set up watchers
@@ -393,10 +448,10 @@ func TestWatchFiles_IgnoresNonGoFiles(t *testing.T) {
 	os.WriteFile("main.go", []byte("package main"), 0644)
 	os.WriteFile("config.yaml", []byte("key: value"), 0644)
 
-	rebuild := make(chan bool, 1)
+	rebuild := make(chan string, 1)
 
 	go func() {
-		watchFiles(rebuild)
+		watchFiles(newWatchMatcher(config.WatchConfig{}), rebuild)
 	}()
 
 	// Give watcher time to set up
@@ -422,10 +477,10 @@ func TestWatchFiles_TriggersOnGoFileChange(t *testing.T) {
 
 	os.WriteFile("main.go", []byte("package main"), 0644)
 
-	rebuild := make(chan bool, 1)
+	rebuild := make(chan string, 1)
 
 	go func() {
-		watchFiles(rebuild)
+		watchFiles(newWatchMatcher(config.WatchConfig{}), rebuild)
 	}()
 
 	// Give watcher time to set up
@@ -451,10 +506,10 @@ func TestWatchFiles_DebounceMultipleChanges(t *testing.T) {
 
 	os.WriteFile("main.go", []byte("package main"), 0644)
 
-	rebuild := make(chan bool, 10)
+	rebuild := make(chan string, 10)
 
 	go func() {
-		watchFiles(rebuild)
+		watchFiles(newWatchMatcher(config.WatchConfig{}), rebuild)
 	}()
 
 	// Give watcher time to set up
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/stubs"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+var stubPublishForce bool
+
+var stubPublishCmd = &cobra.Command{
+	Use:   "stub:publish",
+	Short: "Publish generator stubs for customization",
+	Long: `Copy the generator stubs into the project's stubs/ directory.
+
+Generators use a stub from stubs/ instead of the built-in one when it exists.
+Published stubs are checked against their documented template variables
+every time a generator loads them. See stubs/README.md for the variables.`,
+	RunE: runStubPublish,
+}
+
+func init() {
+	stubPublishCmd.Flags().BoolVar(&stubPublishForce, "force", false, "Overwrite stubs that were already published")
+}
+
+func runStubPublish(cmd *cobra.Command, args []string) error {
+	ui.Header("stub:publish")
+
+	published := 0
+	for _, name := range stubs.Publishable() {
+		content, err := stubs.Get(name)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Failed to read stub %s: %v", name, err))
+			return err
+		}
+
+		outputPath := filepath.Join(stubs.ProjectDir, filepath.FromSlash(name))
+		wrote, err := publishFile(outputPath, content)
+		if err != nil {
+			return err
+		}
+		if wrote {
+			published++
+		}
+	}
+
+	if _, err := publishFile(filepath.Join(stubs.ProjectDir, "README.md"), []byte(stubsReadme())); err != nil {
+		return err
+	}
+
+	ui.Newline()
+	if published == 0 {
+		ui.Info("Nothing to publish")
+		return nil
+	}
+	ui.Success(fmt.Sprintf("Published %d stubs to %s/", published, stubs.ProjectDir))
+	return nil
+}
+
+// publishFile writes content to path unless it exists and --force is not set
+func publishFile(path string, content []byte) (bool, error) {
+	if _, err := os.Stat(path); err == nil && !stubPublishForce {
+		ui.Step(fmt.Sprintf("Skipped: %s (already exists)", path))
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		ui.Error(fmt.Sprintf("Failed to create directory: %v", err))
+		return false, err
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		ui.Error(fmt.Sprintf("Failed to write file: %v", err))
+		return false, err
+	}
+
+	ui.Success(fmt.Sprintf("Published: %s", path))
+	return true, nil
+}
+
+// stubsReadme documents the template variables of every publishable stub
+func stubsReadme() string {
+	var b strings.Builder
+	b.WriteString("# Stubs\n\n")
+	b.WriteString("Generators use these files instead of the built-in stubs. Stubs are Go\n")
+	b.WriteString("`text/template` files; referencing a variable that is not listed below\n")
+	b.WriteString("makes the generator fail with an error naming the stub.\n")
+
+	for _, name := range stubs.Publishable() {
+		fmt.Fprintf(&b, "\n## %s\n\n", name)
+		for _, v := range stubs.Variables[name] {
+			fmt.Fprintf(&b, "- `{{ .%s }}` - %s\n", v.Name, v.Description)
+		}
+	}
+	return b.String()
+}
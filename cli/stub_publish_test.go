@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/velocitykode/velocity-cli/internal/stubs"
+)
+
+func TestRunStubPublish_CopiesStubs(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	stubPublishForce = false
+
+	if err := runStubPublish(nil, nil); err != nil {
+		t.Fatalf("runStubPublish() error = %v", err)
+	}
+
+	for _, name := range stubs.Publishable() {
+		if _, err := os.Stat(filepath.Join("stubs", name)); err != nil {
+			t.Errorf("Stub not published: %s", name)
+		}
+	}
+
+	readme, err := os.ReadFile("stubs/README.md")
+	if err != nil {
+		t.Fatal("stubs/README.md should be written")
+	}
+	if !strings.Contains(string(readme), "`{{ .ControllerName }}`") {
+		t.Error("README should document template variables")
+	}
+}
+
+func TestRunStubPublish_KeepsCustomizedStubs(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	customPath := filepath.Join("stubs", "app", "jobs", "job.go.stub")
+	os.MkdirAll(filepath.Dir(customPath), 0755)
+	os.WriteFile(customPath, []byte("custom"), 0644)
+
+	stubPublishForce = false
+	runStubPublish(nil, nil)
+
+	content, _ := os.ReadFile(customPath)
+	if string(content) != "custom" {
+		t.Error("stub:publish should not overwrite customized stubs without --force")
+	}
+
+	stubPublishForce = true
+	defer func() { stubPublishForce = false }()
+	runStubPublish(nil, nil)
+
+	content, _ = os.ReadFile(customPath)
+	if string(content) == "custom" {
+		t.Error("stub:publish --force should overwrite customized stubs")
+	}
+}
+
+func TestGenerators_UseProjectStub(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	customPath := filepath.Join("stubs", "app", "http", "controllers", "controller.go.stub")
+	os.MkdirAll(filepath.Dir(customPath), 0755)
+	os.WriteFile(customPath, []byte("package {{ .Package }}\n\n// {{ .Name }} is customized\n"), 0644)
+
+	if err := runMakeController(nil, []string{"Post"}); err != nil {
+		t.Fatalf("runMakeController() error = %v", err)
+	}
+
+	content, _ := os.ReadFile("app/http/controllers/post_controller.go")
+	if !strings.Contains(string(content), "// Post is customized") {
+		t.Error("Generator should prefer the project stub over the embedded one")
+	}
+}
+
+func TestGenerators_RejectInvalidProjectStub(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	customPath := filepath.Join("stubs", "app", "http", "controllers", "controller.go.stub")
+	os.MkdirAll(filepath.Dir(customPath), 0755)
+	os.WriteFile(customPath, []byte("package {{ .Pkg }}\n"), 0644)
+
+	if err := runMakeController(nil, []string{"Post"}); err == nil {
+		t.Fatal("runMakeController() should fail with an invalid project stub")
+	}
+
+	if _, err := os.Stat("app"); !os.IsNotExist(err) {
+		t.Error("Failed generation should not leave directories behind")
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+// Paths never watched, whatever velocity.yaml says
+var defaultWatchExcludes = []string{".git", ".velocity", "vendor", "node_modules"}
+
+// Extensions that rebuild the server, whatever velocity.yaml says
+var defaultWatchExtensions = []string{".go"}
+
+// watchMatcher decides what a changed file does to the running server
+type watchMatcher struct {
+	include    []string
+	exclude    []string
+	extensions map[string]bool
+	rules      []config.WatchRule
+}
+
+// newWatchMatcher combines a watch configuration with the defaults
+func newWatchMatcher(cfg config.WatchConfig) *watchMatcher {
+	m := &watchMatcher{
+		include:    cfg.Include,
+		exclude:    append(append([]string{}, defaultWatchExcludes...), cfg.Exclude...),
+		extensions: make(map[string]bool),
+		rules:      cfg.Rules,
+	}
+	for _, ext := range append(append([]string{}, defaultWatchExtensions...), cfg.Extensions...) {
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		m.extensions[ext] = true
+	}
+	return m
+}
+
+// excluded reports whether a path, or any directory containing it,
+// matches an exclude pattern
+func (m *watchMatcher) excluded(name string) bool {
+	name = cleanWatchPath(name)
+	if name == "." {
+		return false
+	}
+	for _, pattern := range m.exclude {
+		if matchGlobOrParent(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// action returns the action for a changed file, or "" when the change
+// is ignored. Rules are checked in order before the watched extensions.
+func (m *watchMatcher) action(name string) string {
+	name = cleanWatchPath(name)
+	if m.excluded(name) {
+		return ""
+	}
+
+	if len(m.include) > 0 {
+		included := false
+		for _, pattern := range m.include {
+			if matchGlobOrParent(pattern, name) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return ""
+		}
+	}
+
+	for _, rule := range m.rules {
+		if matchGlobOrParent(rule.Pattern, name) {
+			return rule.Action
+		}
+	}
+
+	if m.extensions[path.Ext(name)] {
+		return config.WatchRebuild
+	}
+	return ""
+}
+
+// cleanWatchPath turns a watcher path into a clean slash-separated path
+// relative to the project root
+func cleanWatchPath(name string) string {
+	return path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "./"))
+}
+
+// matchGlobOrParent reports whether pattern matches name or one of the
+// directories containing it, so "storage" covers storage/logs/app.log
+// but not storage_test.go
+func matchGlobOrParent(pattern, name string) bool {
+	for {
+		if matchGlob(pattern, name) {
+			return true
+		}
+		parent := path.Dir(name)
+		if parent == "." || parent == name {
+			return false
+		}
+		name = parent
+	}
+}
+
+// matchGlob matches a slash-separated path against a glob. "**" matches
+// any number of directories, and a pattern without a slash matches the
+// last element of the path at any depth.
+func matchGlob(pattern, name string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "app/http/controllers/home.go", true},
+		{"*.go", "main.html", false},
+		{"app/*.go", "app/user.go", true},
+		{"app/*.go", "app/models/user.go", false},
+		{"app/**/*.go", "app/user.go", true},
+		{"app/**/*.go", "app/models/user.go", true},
+		{"resources/**", "resources/views/home.html", true},
+		{"**/*.tmpl", "resources/views/layout.tmpl", true},
+		{"./routes/web.go", "routes/web.go", true},
+		{"storage/", "storage", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchMatcher_Action(t *testing.T) {
+	matcher := newWatchMatcher(config.WatchConfig{
+		Exclude:    []string{"storage", "*_test.go"},
+		Extensions: []string{".html", "yaml"},
+		Rules: []config.WatchRule{
+			{Pattern: "resources/views/**", Action: config.WatchRestart},
+			{Pattern: ".env", Action: config.WatchRestart},
+		},
+	})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"main.go", config.WatchRebuild},
+		{"./app/http/controllers/home_controller.go", config.WatchRebuild},
+		{"app/http/controllers/vendors_controller.go", config.WatchRebuild},
+		{"app/velocity_helpers.go", config.WatchRebuild},
+		{"vendor/pkg/lib.go", ""},
+		{"app/vendor/lib.go", ""},
+		{".velocity/tmp/main.go", ""},
+		{"node_modules/pkg/index.go", ""},
+		{"storage/logs/app.go", ""},
+		{"storage_helpers.go", config.WatchRebuild},
+		{"app/models/user_test.go", ""},
+		{"config/app.yaml", config.WatchRebuild},
+		{"public/index.html", config.WatchRebuild},
+		{"resources/views/home.html", config.WatchRestart},
+		{"resources/views/layout.tmpl", config.WatchRestart},
+		{".env", config.WatchRestart},
+		{"README.md", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.action(tt.name); got != tt.want {
+				t.Errorf("action(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchMatcher_Include(t *testing.T) {
+	matcher := newWatchMatcher(config.WatchConfig{Include: []string{"app", "main.go"}})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"main.go", config.WatchRebuild},
+		{"app/models/user.go", config.WatchRebuild},
+		{"routes/web.go", ""},
+		{"application/server.go", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.action(tt.name); got != tt.want {
+				t.Errorf("action(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProject_WatchConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	project, err := config.LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProject() without velocity.yaml error = %v", err)
+	}
+	if len(project.Serve.Watch.Rules) != 0 {
+		t.Errorf("rules = %v, want none", project.Serve.Watch.Rules)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, config.ProjectFile), []byte(`serve:
+  watch:
+    extensions: [".html"]
+    rules:
+      - pattern: "resources/views/**"
+        action: restart
+`), 0644)
+	project, err = config.LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	watch := project.Serve.Watch
+	if len(watch.Extensions) != 1 || len(watch.Rules) != 1 || watch.Rules[0].Action != config.WatchRestart {
+		t.Errorf("watch config = %+v", watch)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, config.ProjectFile), []byte(`serve:
+  watch:
+    rules:
+      - pattern: "*.css"
+        action: reload
+`), 0644)
+	if _, err := config.LoadProject(tmpDir); err == nil {
+		t.Error("LoadProject() should reject an unknown watch action")
+	}
+}
+
+func TestWatchFiles_RestartRule(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile(".env", []byte("APP_NAME=one"), 0644)
+
+	changes := make(chan string, 1)
+	matcher := newWatchMatcher(config.WatchConfig{
+		Rules: []config.WatchRule{{Pattern: ".env", Action: config.WatchRestart}},
+	})
+	go watchFiles(matcher, changes)
+
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile(".env", []byte("APP_NAME=two"), 0644)
+
+	select {
+	case action := <-changes:
+		if action != config.WatchRestart {
+			t.Errorf("action = %q, want %q", action, config.WatchRestart)
+		}
+	case <-time.After(800 * time.Millisecond):
+		t.Error(".env change should trigger a restart")
+	}
+}
+
+func TestWatchFiles_WatchesNewDirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("main.go", []byte("package main"), 0644)
+
+	changes := make(chan string, 1)
+	go watchFiles(newWatchMatcher(config.WatchConfig{}), changes)
+
+	time.Sleep(100 * time.Millisecond)
+	os.MkdirAll("app/services", 0755)
+	time.Sleep(100 * time.Millisecond)
+	os.WriteFile("app/services/mailer.go", []byte("package services"), 0644)
+
+	select {
+	case action := <-changes:
+		if action != config.WatchRebuild {
+			t.Errorf("action = %q, want %q", action, config.WatchRebuild)
+		}
+	case <-time.After(800 * time.Millisecond):
+		t.Error("change in a directory created after startup should trigger a rebuild")
+	}
+}
+
+func TestWatchFiles_NewDirectoryWithFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.WriteFile("main.go", []byte("package main"), 0644)
+
+	// A package moved in whole arrives as a single directory event
+	staging := filepath.Join(t.TempDir(), "services")
+	os.MkdirAll(staging, 0755)
+	os.WriteFile(filepath.Join(staging, "mailer.go"), []byte("package services"), 0644)
+
+	changes := make(chan string, 1)
+	go watchFiles(newWatchMatcher(config.WatchConfig{}), changes)
+
+	time.Sleep(100 * time.Millisecond)
+	if err := os.Rename(staging, "services"); err != nil {
+		t.Skipf("cannot move directory into project: %v", err)
+	}
+
+	select {
+	case action := <-changes:
+		if action != config.WatchRebuild {
+			t.Errorf("action = %q, want %q", action, config.WatchRebuild)
+		}
+	case <-time.After(800 * time.Millisecond):
+		t.Error("a new directory holding Go files should trigger a rebuild")
+	}
+}
+
+func TestSendWatchAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		waiting string
+		action  string
+		want    string
+	}{
+		{"empty channel", "", config.WatchRestart, config.WatchRestart},
+		{"rebuild replaces waiting restart", config.WatchRestart, config.WatchRebuild, config.WatchRebuild},
+		{"restart keeps waiting rebuild", config.WatchRebuild, config.WatchRestart, config.WatchRebuild},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := make(chan string, 1)
+			if tt.waiting != "" {
+				changes <- tt.waiting
+			}
+
+			sendWatchAction(changes, tt.action)
+
+			if got := <-changes; got != tt.want {
+				t.Errorf("action = %q, want %q", got, tt.want)
+			}
+			if len(changes) != 0 {
+				t.Errorf("%d actions left waiting, want none", len(changes))
+			}
+		})
+	}
+}
+
+func TestLoadProject_ServeSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tmpDir, config.ProjectFile), []byte(`serve:
+  grace_period: 10s
+  health_path: /health
+`), 0644)
+	project, err := config.LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if project.Serve.GracePeriod != 10*time.Second || project.Serve.HealthPath != "/health" {
+		t.Errorf("serve config = %+v", project.Serve)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, config.ProjectFile), []byte("serve:\n  health_path: health\n"), 0644)
+	if _, err := config.LoadProject(tmpDir); err == nil {
+		t.Error("LoadProject() should reject a health path without a leading slash")
+	}
+}
+
+func TestLoadProject_Processes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tmpDir, config.ProjectFile), []byte("serve:\n  processes: []\n"), 0644)
+	project, err := config.LoadProject(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if project.Serve.Processes == nil {
+		t.Error("an empty processes list should stay distinct from a missing one")
+	}
+
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"missing command", "serve:\n  processes:\n    - name: vite\n"},
+		{"duplicate name", "serve:\n  processes:\n    - {name: vite, command: a}\n    - {name: vite, command: b}\n"},
+		{"reserved name", "serve:\n  processes:\n    - {name: app, command: a}\n"},
+		{"bad policy", "serve:\n  processes:\n    - {name: vite, command: a, restart: sometimes}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.WriteFile(filepath.Join(tmpDir, config.ProjectFile), []byte(tt.yaml), 0644)
+			if _, err := config.LoadProject(tmpDir); err == nil {
+				t.Error("LoadProject() should reject this configuration")
+			}
+		})
+	}
+}
@@ -99,6 +99,8 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		cfg.Defaults.Auth = value == "true"
 	case "default.api":
 		cfg.Defaults.API = value == "true"
+	case "default.template":
+		cfg.Defaults.Template = value
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -143,6 +145,8 @@ func runConfigGet(cmd *cobra.Command, args []string) error {
 		} else {
 			value = "false"
 		}
+	case "default.template":
+		value = cfg.Defaults.Template
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -180,6 +184,9 @@ func runConfigList(cmd *cobra.Command, args []string) error {
 	if cfg.Defaults.API {
 		ui.KeyValue("default.api", "true")
 	}
+	if cfg.Defaults.Template != "" {
+		ui.KeyValue("default.template", cfg.Defaults.Template)
+	}
 
 	return nil
 }
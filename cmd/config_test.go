@@ -66,6 +66,7 @@ func TestRunConfigSet_ValidKeys(t *testing.T) {
 		{"default.queue", "redis"},
 		{"default.auth", "true"},
 		{"default.api", "true"},
+		{"default.template", "git@example.com:acme/template.git"},
 	}
 
 	cmd := &cobra.Command{}
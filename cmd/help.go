@@ -32,13 +32,26 @@ var projectCommandGroups = map[string][]commandInfo{
 	"development": {
 		{"serve", "Start the development server with hot reload"},
 		{"build", "Build the application for production"},
+		{"route:list", "List all registered routes"},
 	},
 	"database": {
 		{"migrate", "Run database migrations"},
 		{"migrate:fresh", "Drop all tables and re-run migrations"},
+		{"migrate:rollback", "Rollback the last batch of migrations"},
+		{"migrate:reset", "Rollback all database migrations"},
+		{"migrate:status", "Show the status of each migration"},
 	},
 	"generators": {
 		{"make:controller", "Create a new controller"},
+		{"make:migration", "Create a new migration file"},
+		{"make:model", "Create a new model"},
+		{"make:middleware", "Create a new middleware"},
+		{"make:request", "Create a new form request"},
+		{"make:job", "Create a new queued job"},
+		{"make:event", "Create a new event"},
+		{"make:listener", "Create a new event listener"},
+		{"make:dockerfile", "Create a Dockerfile for the project"},
+		{"stub:publish", "Publish generator stubs for customization"},
 	},
 	"security": {
 		{"key:generate", "Generate application encryption key"},
@@ -17,6 +17,8 @@ var (
 	initAuth          bool
 	initAPI           bool
 	initNoInteraction bool
+	initDryRun        bool
+	initJSON          bool
 )
 
 var InitCmd = &cobra.Command{
@@ -32,10 +34,17 @@ func init() {
 	InitCmd.Flags().BoolVar(&initAuth, "auth", false, "Include authentication")
 	InitCmd.Flags().BoolVar(&initAPI, "api", false, "API-only structure")
 	InitCmd.Flags().BoolVar(&initNoInteraction, "no-interaction", false, "Non-interactive mode")
+	InitCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "Show what would change without writing anything")
+	InitCmd.Flags().BoolVar(&initJSON, "json", false, "Print the --dry-run plan as JSON")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
-	ui.Header("init")
+	if initJSON && !initDryRun {
+		return fmt.Errorf("--json can only be used with --dry-run")
+	}
+	if !initJSON {
+		ui.Header("init")
+	}
 
 	// Get current working directory
 	cwd, err := os.Getwd()
@@ -44,7 +53,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 	}
 
 	// Detect project
-	ui.Info("Detecting Go project...")
+	if !initJSON {
+		ui.Info("Detecting Go project...")
+	}
 	info, err := detector.Detect(cwd)
 	if err != nil {
 		return fmt.Errorf("not a Go project\n\nThis directory does not contain a go.mod file.\nInitialize a Go module first:\n  go mod init github.com/yourname/project\n\nThen run 'velocity init' again.")
@@ -54,7 +65,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Velocity already initialized\n\nThis project already has Velocity structure (app/, config/, routes/).\n\nIf you want to create a new project, use:\n  velocity new project-name")
 	}
 
-	ui.Success(fmt.Sprintf("Detected Go project: %s", info.ModuleName))
+	if !initJSON {
+		ui.Success(fmt.Sprintf("Detected Go project: %s", info.ModuleName))
+	}
 
 	// Load config defaults
 	cfg, _ := config.Load()
@@ -94,6 +107,14 @@ func runInit(cmd *cobra.Command, args []string) error {
 		API:      api,
 	}
 
+	if initDryRun {
+		plan, err := generator.PlanInit(projectCfg, cwd)
+		if err != nil {
+			return err
+		}
+		return printPlan(plan, initJSON)
+	}
+
 	// Initialize project
 	if err := generator.InitProject(projectCfg, cwd); err != nil {
 		return fmt.Errorf("failed to initialize: %w", err)
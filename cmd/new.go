@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/velocitykode/velocity-cli/internal/config"
 	"github.com/velocitykode/velocity-cli/internal/generator"
 	"github.com/velocitykode/velocity-cli/internal/ui"
 )
@@ -13,6 +14,14 @@ var (
 	cache    string
 	auth     bool
 	api      bool
+
+	template    string
+	templateRef string
+
+	newDryRun bool
+	newJSON   bool
+
+	keepOnFailure bool
 )
 
 var NewCmd = &cobra.Command{
@@ -31,25 +40,58 @@ var NewCmd = &cobra.Command{
 			ui.Muted("  --cache       Cache driver (redis, memory)")
 			ui.Muted("  --auth        Include authentication scaffolding")
 			ui.Muted("  --api         API-only structure (no views)")
+			ui.Muted("  --template    Template directory, git URL or .tar.gz archive")
+			ui.Muted("  --ref         Branch, tag or commit of a git template")
+			ui.Muted("  --dry-run     Show what would be created without writing anything")
+			ui.Muted("  --keep-on-failure  Leave a partially created project for debugging")
 			return fmt.Errorf("")
 		}
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		projectName := args[0]
-		ui.Header("velocity new")
+		if newJSON && !newDryRun {
+			ui.Error("--json can only be used with --dry-run")
+			return
+		}
+		if !newJSON {
+			ui.Header("velocity new")
+		}
+
+		// A configured default template applies unless one is given
+		if !cmd.Flags().Changed("template") {
+			if cfg, err := config.Load(); err == nil && cfg.Defaults.Template != "" {
+				template = cfg.Defaults.Template
+			}
+		}
 
 		// Create project with flags (defaults to sqlite if not specified)
-		config := generator.ProjectConfig{
+		projectConfig := generator.ProjectConfig{
 			Name:     projectName,
 			Module:   projectName,
 			Database: database,
 			Cache:    cache,
 			Auth:     auth,
 			API:      api,
+
+			Template:    template,
+			TemplateRef: templateRef,
+
+			KeepOnFailure: keepOnFailure,
+		}
+
+		if newDryRun {
+			plan, err := generator.PlanCreate(projectConfig)
+			if err == nil {
+				err = printPlan(plan, newJSON)
+			}
+			if err != nil {
+				ui.Error(err.Error())
+			}
+			return
 		}
 
-		if err := generator.CreateProject(config); err != nil {
+		if err := generator.CreateProject(projectConfig); err != nil {
 			ui.Newline()
 			ui.Error(err.Error())
 			return
@@ -67,4 +109,9 @@ func init() {
 	NewCmd.Flags().StringVar(&cache, "cache", "memory", "Cache driver (redis, memory)")
 	NewCmd.Flags().BoolVar(&auth, "auth", false, "Include authentication scaffolding")
 	NewCmd.Flags().BoolVar(&api, "api", false, "API-only structure (no views)")
+	NewCmd.Flags().StringVar(&template, "template", "", "Template directory, git URL or .tar.gz archive (default: official template)")
+	NewCmd.Flags().StringVar(&templateRef, "ref", "", "Branch, tag or commit of a git template")
+	NewCmd.Flags().BoolVar(&newDryRun, "dry-run", false, "Show what would be created without writing anything")
+	NewCmd.Flags().BoolVar(&newJSON, "json", false, "Print the --dry-run plan as JSON")
+	NewCmd.Flags().BoolVar(&keepOnFailure, "keep-on-failure", false, "Leave a partially created project on disk instead of rolling it back")
 }
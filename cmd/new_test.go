@@ -52,6 +52,11 @@ func TestNewCmd_FlagDefaults(t *testing.T) {
 		{"cache", "memory"},
 		{"auth", "false"},
 		{"api", "false"},
+		{"template", ""},
+		{"ref", ""},
+		{"dry-run", "false"},
+		{"json", "false"},
+		{"keep-on-failure", "false"},
 	}
 
 	for _, tt := range tests {
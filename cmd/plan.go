@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/velocitykode/velocity-cli/internal/generator"
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// printPlan shows a dry-run plan as a tree, or as JSON for tooling
+func printPlan(plan *generator.Plan, asJSON bool) error {
+	if asJSON {
+		out, err := plan.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	ui.Newline()
+	plan.Print()
+	ui.Newline()
+	ui.Muted("Dry run: nothing was written. Run again without --dry-run to apply.")
+	return nil
+}
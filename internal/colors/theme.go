@@ -47,3 +47,13 @@ var (
 	MutedStyle = lipgloss.NewStyle().
 			Foreground(Muted)
 )
+
+// ProcessColors label the output of processes run side by side, in order
+var ProcessColors = []lipgloss.Color{
+	Primary,
+	Success,
+	Warning,
+	lipgloss.Color("#a855f7"),
+	lipgloss.Color("#ec4899"),
+	lipgloss.Color("#14b8a6"),
+}
@@ -21,6 +21,7 @@ type DefaultConfig struct {
 	Queue    string `yaml:"queue,omitempty"`
 	Auth     bool   `yaml:"auth,omitempty"`
 	API      bool   `yaml:"api,omitempty"`
+	Template string `yaml:"template,omitempty"`
 }
 
 var validDatabases = []string{"postgres", "mysql", "sqlite"}
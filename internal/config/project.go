@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the per-project configuration file, read from the
+// project root
+const ProjectFile = "velocity.yaml"
+
+// Watch actions
+const (
+	WatchRebuild = "rebuild" // Rebuild the binary and restart the server
+	WatchRestart = "restart" // Restart the server without rebuilding
+)
+
+// Project represents the configuration in velocity.yaml
+type Project struct {
+	Serve ServeConfig `yaml:"serve"`
+	Build BuildConfig `yaml:"build"`
+}
+
+// BuildConfig configures production builds
+type BuildConfig struct {
+	// Frontend is the command that builds the frontend. When empty, the
+	// build script in package.json is run with bun or npm.
+	Frontend string `yaml:"frontend,omitempty"`
+
+	// Assets is the directory the frontend build writes to, relative to
+	// the project root; build --assets embeds it in the binary
+	Assets string `yaml:"assets,omitempty"`
+
+	// CC is the C compiler used for cgo builds, by os/arch target, such as
+	// linux/arm64: aarch64-linux-gnu-gcc
+	CC map[string]string `yaml:"cc,omitempty"`
+}
+
+// ServeConfig configures the development server
+type ServeConfig struct {
+	Watch WatchConfig `yaml:"watch"`
+
+	// GracePeriod is how long a server gets to exit after SIGTERM before
+	// it is killed
+	GracePeriod time.Duration `yaml:"grace_period,omitempty"`
+
+	// HealthPath is polled over HTTP until it answers before a restarted
+	// server is reported ready. When empty, an open port is enough.
+	HealthPath string `yaml:"health_path,omitempty"`
+
+	// ReadyTimeout bounds how long readiness is waited for
+	ReadyTimeout time.Duration `yaml:"ready_timeout,omitempty"`
+
+	// Processes run alongside the app, such as the frontend dev server or
+	// a queue worker. When left out, the frontend dev server is started if
+	// package.json has a dev script; an empty list starts nothing.
+	Processes []ProcessConfig `yaml:"processes"`
+}
+
+// Process restart policies
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "never"
+)
+
+// ProcessConfig is a named process supervised by serve
+type ProcessConfig struct {
+	Name    string            `yaml:"name"`
+	Command string            `yaml:"command"`
+	Dir     string            `yaml:"dir,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+
+	// Restart is always, on-failure (the default) or never
+	Restart string `yaml:"restart,omitempty"`
+
+	// MaxRestarts limits restarts in a row; 0 means unlimited
+	MaxRestarts int `yaml:"max_restarts,omitempty"`
+}
+
+// WatchConfig controls which file changes reload the development server.
+// Exclude and extensions add to the built-in defaults.
+type WatchConfig struct {
+	Include    []string    `yaml:"include,omitempty"`
+	Exclude    []string    `yaml:"exclude,omitempty"`
+	Extensions []string    `yaml:"extensions,omitempty"`
+	Rules      []WatchRule `yaml:"rules,omitempty"`
+}
+
+// WatchRule sets the action for files matching a glob pattern
+type WatchRule struct {
+	Pattern string `yaml:"pattern"`
+	Action  string `yaml:"action"`
+}
+
+// LoadProject reads velocity.yaml from dir. A missing file yields an
+// empty configuration.
+func LoadProject(dir string) (*Project, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ProjectFile))
+	if os.IsNotExist(err) {
+		return &Project{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var project Project
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ProjectFile, err)
+	}
+	if err := project.Serve.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ProjectFile, err)
+	}
+	if err := project.Build.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ProjectFile, err)
+	}
+
+	return &project, nil
+}
+
+// Validate checks the serve settings
+func (s ServeConfig) Validate() error {
+	if s.GracePeriod < 0 || s.ReadyTimeout < 0 {
+		return fmt.Errorf("serve durations cannot be negative")
+	}
+	if s.HealthPath != "" && !strings.HasPrefix(s.HealthPath, "/") {
+		return fmt.Errorf("health_path must start with /: %s", s.HealthPath)
+	}
+
+	names := map[string]bool{"app": true}
+	for _, p := range s.Processes {
+		if p.Name == "" || p.Command == "" {
+			return fmt.Errorf("every process needs a name and a command")
+		}
+		if names[p.Name] {
+			return fmt.Errorf("duplicate process name: %s", p.Name)
+		}
+		names[p.Name] = true
+		switch p.Restart {
+		case "", RestartAlways, RestartOnFailure, RestartNever:
+		default:
+			return fmt.Errorf("invalid restart policy %q for %s (must be: always, on-failure, never)", p.Restart, p.Name)
+		}
+		if p.MaxRestarts < 0 {
+			return fmt.Errorf("max_restarts cannot be negative for %s", p.Name)
+		}
+	}
+
+	return s.Watch.Validate()
+}
+
+// Validate checks the build settings
+func (b BuildConfig) Validate() error {
+	if b.Assets != "" {
+		clean := filepath.Clean(b.Assets)
+		if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("build assets must be a directory inside the project: %s", b.Assets)
+		}
+	}
+	for target, cc := range b.CC {
+		goos, goarch, ok := strings.Cut(target, "/")
+		if !ok || goos == "" || goarch == "" {
+			return fmt.Errorf("invalid build cc target %q (expected os/arch, e.g. linux/arm64)", target)
+		}
+		if cc == "" {
+			return fmt.Errorf("empty build cc for %s", target)
+		}
+	}
+	return nil
+}
+
+// Validate checks the watch patterns and actions
+func (w WatchConfig) Validate() error {
+	patterns := append(append([]string{}, w.Include...), w.Exclude...)
+	for _, rule := range w.Rules {
+		if rule.Action != WatchRebuild && rule.Action != WatchRestart {
+			return fmt.Errorf("invalid watch action %q for %s (must be: rebuild, restart)", rule.Action, rule.Pattern)
+		}
+		patterns = append(patterns, rule.Pattern)
+	}
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return fmt.Errorf("empty watch pattern")
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid watch pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -25,8 +25,8 @@ func generateProjectFiles(config ProjectConfig) error {
 	return nil
 }
 
-func generateEnvFile(config ProjectConfig) error {
-	envTemplate := `# Application
+// envTemplate is the .env.example and .env content init generates
+const envTemplate = `# Application
 APP_NAME={{ .Name }}
 APP_ENV=development
 APP_PORT=4000
@@ -61,6 +61,7 @@ LOG_LEVEL=debug
 LOG_OUTPUT=stdout
 `
 
+func generateEnvFile(config ProjectConfig) error {
 	filePath := filepath.Join(config.Name, ".env.example")
 	if err := executeTemplate(filePath, envTemplate, config); err != nil {
 		return err
@@ -200,6 +201,9 @@ MIT
 	return executeTemplate(filePath, readmeTemplate, config)
 }
 
+// projectFiles are the configuration files init generates
+var projectFiles = []string{".env.example", ".env", ".gitignore", "README.md"}
+
 func executeTemplate(filePath, tmplContent string, data interface{}) error {
 	tmpl, err := template.New("file").Parse(tmplContent)
 	if err != nil {
@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+)
+
+// errInterrupted is returned by a journal run stopped by SIGINT or SIGTERM
+var errInterrupted = errors.New("interrupted")
+
+// journal records an undo action for every completed step of project
+// creation so a failure can put the disk back the way it was
+type journal struct {
+	mu          sync.Mutex
+	entries     []journalEntry
+	interrupted atomic.Bool
+}
+
+type journalEntry struct {
+	name string
+	undo func() error
+}
+
+// record registers undo for a step. Undo actions run newest first.
+func (j *journal) record(name string, undo func() error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, journalEntry{name: name, undo: undo})
+}
+
+// rollback runs every undo action newest first and clears the journal.
+// It returns the names of the steps undone and any undo errors.
+func (j *journal) rollback() ([]string, []error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var undone []string
+	var errs []error
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		entry := j.entries[i]
+		if err := entry.undo(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+			continue
+		}
+		undone = append(undone, entry.name)
+	}
+	j.entries = nil
+	return undone, errs
+}
+
+// run runs steps in order until one fails. An interrupt lets the running
+// step finish, so nothing is rolled back under it, and stops the rest.
+func (j *journal) run(steps ...func() error) error {
+	for _, step := range steps {
+		if j.interrupted.Load() {
+			return errInterrupted
+		}
+		if err := step(); err != nil {
+			if j.interrupted.Load() {
+				return errInterrupted
+			}
+			return err
+		}
+	}
+	if j.interrupted.Load() {
+		return errInterrupted
+	}
+	return nil
+}
+
+// notifyInterrupt marks j interrupted when SIGINT or SIGTERM arrives
+// before the returned stop function is called
+func notifyInterrupt(j *journal) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-sigs:
+			j.interrupted.Store(true)
+			ui.Newline()
+			ui.Warning("Interrupted, stopping after the current step...")
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
+	}
+}
+
+// restoreFiles returns an undo action that puts paths back as they are
+// now. Paths that do not exist yet are removed.
+func restoreFiles(paths ...string) (func() error, error) {
+	originals := make(map[string][]byte, len(paths))
+	modes := make(map[string]os.FileMode, len(paths))
+	var missing []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			missing = append(missing, path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		originals[path] = content
+		modes[path] = info.Mode().Perm()
+	}
+
+	return func() error {
+		var errs []error
+		for path, content := range originals {
+			if err := os.WriteFile(path, content, modes[path]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for _, path := range missing {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+// removeAdded returns an undo action that removes the entries added to
+// dir after it is called, or dir itself if it does not exist yet
+func removeAdded(dir string) func() error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return func() error { return os.RemoveAll(dir) }
+	}
+	existing := map[string]bool{}
+	for _, e := range entries {
+		existing[e.Name()] = true
+	}
+
+	return func() error {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for _, e := range entries {
+			if !existing[e.Name()] {
+				if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// goInstalledPath returns where go install puts the named tool
+func goInstalledPath(name string) string {
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if out, err := exec.Command("go", "env", "GOBIN").Output(); err == nil {
+		if gobin := strings.TrimSpace(string(out)); gobin != "" {
+			return filepath.Join(gobin, name)
+		}
+	}
+	out, err := exec.Command("go", "env", "GOPATH").Output()
+	if err != nil {
+		return ""
+	}
+	gopath := filepath.SplitList(strings.TrimSpace(string(out)))
+	if len(gopath) == 0 {
+		return ""
+	}
+	return filepath.Join(gopath[0], "bin", name)
+}
@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournal_RollbackNewestFirst(t *testing.T) {
+	j := &journal{}
+	var order []string
+	j.record("first", func() error {
+		order = append(order, "first")
+		return nil
+	})
+	j.record("second", func() error {
+		order = append(order, "second")
+		return errors.New("boom")
+	})
+	j.record("third", func() error {
+		order = append(order, "third")
+		return nil
+	})
+
+	undone, errs := j.rollback()
+
+	if want := []string{"third", "second", "first"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("undo order = %v, want %v", order, want)
+	}
+	if want := []string{"third", "first"}; !reflect.DeepEqual(undone, want) {
+		t.Errorf("undone = %v, want %v", undone, want)
+	}
+	if len(errs) != 1 || errs[0].Error() != "second: boom" {
+		t.Errorf("errs = %v, want [second: boom]", errs)
+	}
+
+	// The journal is empty once rolled back
+	if undone, errs := j.rollback(); len(undone) != 0 || len(errs) != 0 {
+		t.Errorf("second rollback = %v, %v, want nothing", undone, errs)
+	}
+}
+
+func TestJournal_RunStopsWhenInterrupted(t *testing.T) {
+	j := &journal{}
+	var ran []string
+	err := j.run(
+		func() error {
+			ran = append(ran, "first")
+			// The signal arrives while this step is running
+			j.interrupted.Store(true)
+			return nil
+		},
+		func() error {
+			ran = append(ran, "second")
+			return nil
+		},
+	)
+
+	if !errors.Is(err, errInterrupted) {
+		t.Errorf("run() error = %v, want errInterrupted", err)
+	}
+	if want := []string{"first"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran = %v, want %v", ran, want)
+	}
+}
+
+func TestRestoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "go.mod")
+	added := filepath.Join(dir, "go.sum")
+	writeTestFile(t, existing, "module {{MODULE_NAME}}\n")
+
+	undo, err := restoreFiles(existing, added)
+	if err != nil {
+		t.Fatalf("restoreFiles() error = %v", err)
+	}
+	writeTestFile(t, existing, "module shop\n")
+	writeTestFile(t, added, "checksums\n")
+
+	if err := undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "module {{MODULE_NAME}}\n" {
+		t.Errorf("go.mod = %q, want the original content", content)
+	}
+	if _, err := os.Stat(added); !os.IsNotExist(err) {
+		t.Error("go.sum did not exist before and should be removed")
+	}
+}
+
+func TestRemoveAdded(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "package.json"), "{}")
+
+	undo := removeAdded(dir)
+	writeTestFile(t, filepath.Join(dir, "node_modules", "vite", "index.js"), "")
+	writeTestFile(t, filepath.Join(dir, "package-lock.json"), "{}")
+
+	if err := undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 || entries[0].Name() != "package.json" {
+		t.Errorf("entries = %v, want only package.json", entries)
+	}
+
+	missing := filepath.Join(dir, "database")
+	undo = removeAdded(missing)
+	writeTestFile(t, filepath.Join(missing, "database.db"), "")
+	if err := undo(); err != nil {
+		t.Fatalf("undo() error = %v", err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("a directory created after removeAdded should be removed")
+	}
+}
+
+func TestCreateProject_RollsBackOnFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	// A template without go.mod fails once the module is configured
+	template := filepath.Join(tmpDir, "template")
+	writeTestFile(t, filepath.Join(template, "README.md"), "# template\n")
+
+	tests := []struct {
+		name string
+		keep bool
+	}{
+		{"removed", false},
+		{"kept", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CreateProject(ProjectConfig{Name: tt.name, Template: template, KeepOnFailure: tt.keep})
+			if err == nil {
+				t.Fatal("CreateProject() error = nil, want failure")
+			}
+
+			_, statErr := os.Stat(filepath.Join(tt.name, "README.md"))
+			if tt.keep && statErr != nil {
+				t.Errorf("project was removed despite KeepOnFailure: %v", statErr)
+			}
+			if !tt.keep && !os.IsNotExist(statErr) {
+				t.Errorf("project was not removed after failure (stat error %v)", statErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/velocitykode/velocity-cli/internal/ui"
+	"golang.org/x/mod/modfile"
+)
+
+// File plan actions
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionSkip      = "skip"
+)
+
+// Plan describes everything new or init would do, without doing it
+type Plan struct {
+	Command      string        `json:"command"`
+	Directory    string        `json:"directory"`
+	Template     string        `json:"template,omitempty"`
+	Files        []PlannedFile `json:"files"`
+	Commands     []string      `json:"commands"`
+	Dependencies []string      `json:"dependencies"`
+	Env          []PlannedEnv  `json:"env"`
+}
+
+// PlannedFile is a file or directory the plan creates or leaves alone.
+// Directories end in a slash.
+type PlannedFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PlannedEnv is an environment key written to an env file
+type PlannedEnv struct {
+	File  string `json:"file"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// newPlan returns an empty plan whose lists encode as [] rather than null
+func newPlan(command, directory string) *Plan {
+	return &Plan{
+		Command:      command,
+		Directory:    directory,
+		Files:        []PlannedFile{},
+		Commands:     []string{},
+		Dependencies: []string{},
+		Env:          []PlannedEnv{},
+	}
+}
+
+// PlanInit returns what InitProject would do to the project in targetDir
+func PlanInit(config ProjectConfig, targetDir string) (*Plan, error) {
+	plan := newPlan("init", targetDir)
+
+	for _, dir := range projectDirectories {
+		plan.addDir(filepath.Join(targetDir, dir), dir+"/")
+	}
+	for _, file := range initStubFiles(config) {
+		plan.addFile(filepath.Join(targetDir, filepath.FromSlash(file.Path)), file.Path)
+	}
+	for _, file := range projectFiles {
+		plan.addFile(filepath.Join(targetDir, file), file)
+	}
+
+	env, err := renderEnvTemplate(config)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range []string{".env.example", ".env"} {
+		plan.Env = append(plan.Env, parseEnvKeys(file, env)...)
+	}
+
+	if hasLocalVelocity() {
+		plan.Commands = append(plan.Commands, "go mod edit -replace github.com/velocitykode/velocity="+localVelocityPath)
+	} else {
+		plan.Commands = append(plan.Commands, "go get github.com/velocitykode/velocity")
+	}
+	plan.Dependencies = append(plan.Dependencies, "github.com/velocitykode/velocity")
+
+	for _, dep := range featureDependencies(config) {
+		plan.Commands = append(plan.Commands, "go get "+dep.Module)
+		plan.Dependencies = append(plan.Dependencies, dep.Module)
+	}
+	plan.Commands = append(plan.Commands, "go mod tidy")
+
+	return plan, nil
+}
+
+// PlanCreate returns what CreateProject would do. Template files are
+// listed when the template is available locally or cached; a git or
+// remote template that has not been fetched yet is shown as one entry.
+func PlanCreate(config ProjectConfig) (*Plan, error) {
+	if err := validateProjectName(config.Name); err != nil {
+		return nil, err
+	}
+
+	source, err := resolveTemplate(config.Template, config.TemplateRef)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleName := config.Module
+	if moduleName == "" {
+		moduleName = config.Name
+	}
+
+	plan := newPlan("new", config.Name)
+	plan.Template = source.describe()
+
+	// Work out where the template files can be read from without fetching
+	localDir := ""
+	switch source.Kind {
+	case templateDir:
+		localDir = source.Location
+	case templateArchive:
+		if isRemoteURL(source.Location) {
+			plan.Commands = append(plan.Commands, "download "+source.Location)
+		}
+	case templateGit:
+		plan.Commands = append(plan.Commands, source.cloneCommand())
+	}
+	if source.Kind != templateDir {
+		if cacheDir, err := TemplateCacheDir(); err == nil {
+			if _, err := os.Stat(filepath.Join(cacheDir, source.cacheKey())); err == nil {
+				localDir = filepath.Join(cacheDir, source.cacheKey())
+			}
+		}
+	}
+
+	var files []string
+	switch {
+	case localDir != "":
+		files, err = listTemplateDir(localDir)
+	case source.Kind == templateArchive && !isRemoteURL(source.Location):
+		files, err = listArchive(source.Location)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if files == nil {
+		plan.Files = append(plan.Files, PlannedFile{Path: "./", Action: ActionCreate, Reason: "template contents"})
+	}
+	for _, file := range files {
+		plan.Files = append(plan.Files, PlannedFile{Path: file, Action: ActionCreate})
+	}
+	for _, file := range defaultMigrationFiles {
+		plan.Files = append(plan.Files, PlannedFile{Path: "database/migrations/" + file, Action: ActionCreate})
+	}
+	plan.Files = append(plan.Files, PlannedFile{Path: ".env", Action: ActionCreate, Reason: "copied from .env.example"})
+
+	if pinsLatestFramework(source) {
+		plan.Commands = append(plan.Commands, "go mod edit -require=github.com/velocitykode/velocity@<latest release>")
+	}
+	plan.Commands = append(plan.Commands,
+		"git init",
+		"git add .",
+		`git commit -m "Initial commit"`,
+		"go mod tidy",
+		"bun install (npm install if bun is unavailable)",
+	)
+	if !isAirInstalled() {
+		plan.Commands = append(plan.Commands, "go install github.com/air-verse/air@latest")
+	}
+	plan.Commands = append(plan.Commands, "go run migrations (.velocity/tmp/migrate)")
+
+	plan.Dependencies = templateDependencies(localDir)
+	if len(plan.Dependencies) == 0 {
+		plan.Dependencies = []string{"github.com/velocitykode/velocity"}
+	}
+
+	// .env is the template's .env.example with a fresh key
+	if localDir != "" {
+		if content, err := os.ReadFile(filepath.Join(localDir, ".env.example")); err == nil {
+			content = []byte(strings.ReplaceAll(string(content), "{{MODULE_NAME}}", moduleName))
+			for _, env := range parseEnvKeys(".env", string(content)) {
+				if env.Key != "CRYPTO_KEY" {
+					plan.Env = append(plan.Env, env)
+				}
+			}
+		}
+	}
+	plan.Env = append(plan.Env, PlannedEnv{File: ".env", Key: "CRYPTO_KEY", Value: "base64:<generated>"})
+
+	return plan, nil
+}
+
+// Print renders the plan as a tree
+func (p *Plan) Print() {
+	var files []ui.TreeNode
+	for _, f := range p.Files {
+		note := f.Action
+		if f.Reason != "" {
+			note += " (" + f.Reason + ")"
+		}
+		files = append(files, ui.TreeNode{Label: f.Path, Note: note})
+	}
+
+	var commands []ui.TreeNode
+	for _, c := range p.Commands {
+		commands = append(commands, ui.TreeNode{Label: c})
+	}
+
+	var deps []ui.TreeNode
+	for _, d := range p.Dependencies {
+		deps = append(deps, ui.TreeNode{Label: d})
+	}
+
+	// Group env keys under the file they are written to
+	var env []ui.TreeNode
+	for _, e := range p.Env {
+		if len(env) == 0 || env[len(env)-1].Label != e.File {
+			env = append(env, ui.TreeNode{Label: e.File})
+		}
+		last := &env[len(env)-1]
+		last.Children = append(last.Children, ui.TreeNode{Label: e.Key + "=" + e.Value})
+	}
+
+	root := p.Directory
+	if p.Template != "" {
+		root += " from " + p.Template
+	}
+	ui.Tree(root, []ui.TreeNode{
+		{Label: "Files", Note: p.fileSummary(), Children: files},
+		{Label: "Commands", Children: commands},
+		{Label: "Dependencies", Children: deps},
+		{Label: "Environment", Children: env},
+	})
+}
+
+// JSON returns the plan as indented JSON
+func (p *Plan) JSON() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// addFile plans writing path, which replaces it when it already exists
+func (p *Plan) addFile(path, display string) {
+	if _, err := os.Stat(path); err == nil {
+		p.Files = append(p.Files, PlannedFile{Path: display, Action: ActionOverwrite, Reason: "already exists"})
+		return
+	}
+	p.Files = append(p.Files, PlannedFile{Path: display, Action: ActionCreate})
+}
+
+// addDir plans creating the directory path, skipping it when it already
+// exists
+func (p *Plan) addDir(path, display string) {
+	if _, err := os.Stat(path); err == nil {
+		p.Files = append(p.Files, PlannedFile{Path: display, Action: ActionSkip, Reason: "already exists"})
+		return
+	}
+	p.Files = append(p.Files, PlannedFile{Path: display, Action: ActionCreate})
+}
+
+// fileSummary counts the planned files by action
+func (p *Plan) fileSummary() string {
+	created, overwritten, skipped := 0, 0, 0
+	for _, f := range p.Files {
+		switch f.Action {
+		case ActionOverwrite:
+			overwritten++
+		case ActionSkip:
+			skipped++
+		default:
+			created++
+		}
+	}
+	return fmt.Sprintf("%d to create, %d to overwrite, %d to skip", created, overwritten, skipped)
+}
+
+// describe returns a short label for the template source
+func (s templateSource) describe() string {
+	location := s.Location
+	if s.isDefault() {
+		location = defaultTemplateURLs[len(defaultTemplateURLs)-1]
+	}
+	if s.Ref != "" {
+		location += "@" + s.Ref
+	}
+	return location
+}
+
+// cloneCommand returns the git command that fetches a git template
+func (s templateSource) cloneCommand() string {
+	command := "git clone --depth=1"
+	if s.Ref != "" {
+		command += " --branch " + s.Ref
+	}
+	return command + " " + s.urls()[0]
+}
+
+// renderEnvTemplate renders the env file init writes for config
+func renderEnvTemplate(config ProjectConfig) (string, error) {
+	tmpl, err := template.New("env").Parse(envTemplate)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, config); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parseEnvKeys returns the KEY=VALUE entries of env file content
+func parseEnvKeys(file, content string) []PlannedEnv {
+	var env []PlannedEnv
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		env = append(env, PlannedEnv{File: file, Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return env
+}
+
+// listTemplateDir returns the files in a template directory, sorted and
+// relative to it, leaving out git metadata
+func listTemplateDir(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// listArchive extracts a template archive into a scratch directory and
+// lists it, so the plan shows exactly what extraction produces
+func listArchive(archive string) ([]string, error) {
+	tmp, err := os.MkdirTemp("", "velocity-plan-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extractArchive(archive, tmp); err != nil {
+		return nil, err
+	}
+	return listTemplateDir(tmp)
+}
+
+// templateDependencies returns the modules a template's go.mod requires
+func templateDependencies(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil
+	}
+
+	// The module line may still hold a placeholder, which is not a valid path
+	data = []byte(strings.ReplaceAll(string(data), "{{MODULE_NAME}}", "app"))
+	file, err := modfile.Parse("go.mod", data, nil)
+	if err != nil {
+		return nil
+	}
+
+	var deps []string
+	for _, r := range file.Require {
+		if !r.Indirect {
+			deps = append(deps, r.Mod.Path+"@"+r.Mod.Version)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func findPlannedFile(plan *Plan, path string) (PlannedFile, bool) {
+	for _, f := range plan.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return PlannedFile{}, false
+}
+
+func TestPlanInit(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n")
+	writeTestFile(t, filepath.Join(dir, "README.md"), "# App\n")
+	writeTestFile(t, filepath.Join(dir, ".env"), "APP_NAME=app\n")
+	os.MkdirAll(filepath.Join(dir, "public"), 0755)
+
+	plan, err := PlanInit(ProjectConfig{Name: ".", Database: "mysql", Cache: "redis", API: true}, dir)
+	if err != nil {
+		t.Fatalf("PlanInit() error = %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		action string
+	}{
+		{"README.md", ActionOverwrite},
+		{".env", ActionOverwrite},
+		{"public/", ActionSkip},
+		{".env.example", ActionCreate},
+		{"routes/api.go", ActionCreate},
+		{"app/http/controllers/", ActionCreate},
+	}
+	for _, tt := range tests {
+		f, ok := findPlannedFile(plan, tt.path)
+		if !ok {
+			t.Errorf("Plan is missing %s", tt.path)
+			continue
+		}
+		if f.Action != tt.action {
+			t.Errorf("%s action = %q, want %q", tt.path, f.Action, tt.action)
+		}
+	}
+
+	if _, ok := findPlannedFile(plan, "app/middleware/auth.go"); ok {
+		t.Error("Auth files should only be planned with --auth")
+	}
+
+	deps := strings.Join(plan.Dependencies, " ")
+	if !strings.Contains(deps, "github.com/go-sql-driver/mysql") || !strings.Contains(deps, "github.com/redis/go-redis/v9") {
+		t.Errorf("Missing feature dependencies: %v", plan.Dependencies)
+	}
+	if plan.Commands[len(plan.Commands)-1] != "go mod tidy" {
+		t.Errorf("Plan should end with go mod tidy, got %v", plan.Commands)
+	}
+
+	found := false
+	for _, env := range plan.Env {
+		if env.File == ".env.example" && env.Key == "DB_CONNECTION" && env.Value == "mysql" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected DB_CONNECTION=mysql in .env.example, got %+v", plan.Env)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Errorf("PlanInit() should not write anything, directory has %d entries", len(entries))
+	}
+}
+
+func TestPlanCreate_LocalTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	writeTestFile(t, filepath.Join("tpl", "go.mod"), "module {{MODULE_NAME}}\n\nrequire github.com/velocitykode/velocity v0.0.3\n")
+	writeTestFile(t, filepath.Join("tpl", ".env.example"), "APP_NAME={{MODULE_NAME}}\nCRYPTO_KEY=\n")
+	writeTestFile(t, filepath.Join("tpl", "routes", "web.go"), "package routes\n")
+	writeTestFile(t, filepath.Join("tpl", ".git", "HEAD"), "ref: main\n")
+
+	plan, err := PlanCreate(ProjectConfig{Name: "blog", Template: "tpl"})
+	if err != nil {
+		t.Fatalf("PlanCreate() error = %v", err)
+	}
+
+	for _, path := range []string{"go.mod", "routes/web.go", ".env", "database/migrations/" + defaultMigrationFiles[0]} {
+		if _, ok := findPlannedFile(plan, path); !ok {
+			t.Errorf("Plan is missing %s", path)
+		}
+	}
+	if _, ok := findPlannedFile(plan, ".git/HEAD"); ok {
+		t.Error("Template git metadata should not be planned")
+	}
+
+	if len(plan.Dependencies) != 1 || plan.Dependencies[0] != "github.com/velocitykode/velocity@v0.0.3" {
+		t.Errorf("Dependencies = %v, want the template's requirement", plan.Dependencies)
+	}
+	for _, c := range plan.Commands {
+		if strings.HasPrefix(c, "git clone") || strings.Contains(c, "-require=") {
+			t.Errorf("Local templates should not be cloned or re-pinned: %s", c)
+		}
+	}
+
+	env := map[string]string{}
+	for _, e := range plan.Env {
+		env[e.Key] = e.Value
+	}
+	if env["APP_NAME"] != "blog" || env["CRYPTO_KEY"] != "base64:<generated>" {
+		t.Errorf("Unexpected env plan: %v", env)
+	}
+
+	if _, err := os.Stat("blog"); !os.IsNotExist(err) {
+		t.Error("PlanCreate() should not create the project")
+	}
+}
+
+func TestPlanCreate_RemoteTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+	t.Setenv("HOME", t.TempDir())
+
+	plan, err := PlanCreate(ProjectConfig{Name: "blog", Template: "https://github.com/acme/template.git", TemplateRef: "v1.2.0"})
+	if err != nil {
+		t.Fatalf("PlanCreate() error = %v", err)
+	}
+
+	if plan.Commands[0] != "git clone --depth=1 --branch v1.2.0 https://github.com/acme/template.git" {
+		t.Errorf("First command = %q", plan.Commands[0])
+	}
+	if f, ok := findPlannedFile(plan, "./"); !ok || f.Reason != "template contents" {
+		t.Error("An unfetched template should be planned as a single entry")
+	}
+}
+
+func TestPlanCreate_ExistingDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	os.Mkdir("blog", 0755)
+	if _, err := PlanCreate(ProjectConfig{Name: "blog"}); err == nil {
+		t.Error("PlanCreate() should fail like CreateProject when the directory exists")
+	}
+}
+
+func TestPlan_JSON(t *testing.T) {
+	plan := newPlan("init", "/app")
+	plan.Files = append(plan.Files, PlannedFile{Path: "main.go", Action: ActionCreate})
+
+	out, err := plan.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	for _, key := range []string{"files", "commands", "dependencies", "env"} {
+		if _, ok := decoded[key].([]interface{}); !ok {
+			t.Errorf("%s should encode as a list, got %v", key, decoded[key])
+		}
+	}
+}
+
+func TestGenerateProjectFiles_OverwritesExisting(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "README.md"), "# Existing\n")
+
+	if err := generateProjectFiles(ProjectConfig{Name: dir}); err != nil {
+		t.Fatalf("generateProjectFiles() error = %v", err)
+	}
+
+	// The plan reports existing files as overwritten, which must stay true
+	content, _ := os.ReadFile(filepath.Join(dir, "README.md"))
+	if string(content) == "# Existing\n" {
+		t.Error("README.md should be regenerated")
+	}
+}
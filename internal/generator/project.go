@@ -4,15 +4,18 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/velocitykode/velocity-cli/internal/delegator"
 	"github.com/velocitykode/velocity-cli/internal/ui"
 )
 
@@ -54,146 +57,287 @@ type ProjectConfig struct {
 	Cache    string
 	Auth     bool
 	API      bool
+
+	// Template is a local directory, git URL or .tar.gz archive to create
+	// the project from. Empty uses the official template.
+	Template    string
+	TemplateRef string // Branch, tag or commit for git templates
+
+	// KeepOnFailure leaves a partially created project on disk for
+	// debugging instead of rolling it back
+	KeepOnFailure bool
 }
 
-// CreateProject generates a new Velocity project from template
+// CreateProject generates a new Velocity project from template. If a step
+// fails or the command is interrupted, completed steps are rolled back
+// unless config.KeepOnFailure is set.
 func CreateProject(config ProjectConfig) error {
 	// Validate project name
 	if err := validateProjectName(config.Name); err != nil {
 		return err
 	}
 
+	j := &journal{}
+	stop := notifyInterrupt(j)
+	err := createProject(config, j)
+	stop()
+	if err != nil {
+		ui.Newline()
+		abortProject(j, config)
+		if errors.Is(err, errInterrupted) {
+			os.Exit(130)
+		}
+		return err
+	}
+	return nil
+}
+
+// abortProject rolls back a failed project, or keeps it when asked to
+func abortProject(j *journal, config ProjectConfig) {
+	if config.KeepOnFailure {
+		ui.Warning(fmt.Sprintf("Kept partially created project in %s (--keep-on-failure)", config.Name))
+		return
+	}
+
+	undone, errs := j.rollback()
+	for _, name := range undone {
+		ui.Muted(fmt.Sprintf("Rolled back: %s", name))
+	}
+	for _, err := range errs {
+		ui.Error(fmt.Sprintf("Rollback failed: %v", err))
+	}
+	if len(errs) > 0 {
+		ui.Muted("Remove what is left by hand, or re-run with --keep-on-failure to inspect it")
+	}
+}
+
+// createProject runs the creation steps, recording an undo action in j
+// before each one starts to change the disk
+func createProject(config ProjectConfig, j *journal) error {
 	// Determine module name
 	moduleName := config.Module
 	if moduleName == "" {
 		moduleName = config.Name
 	}
 
+	source, err := resolveTemplate(config.Template, config.TemplateRef)
+	if err != nil {
+		return err
+	}
+
 	ui.Info("Creating new Velocity project")
 
-	// Clone template
-	if err := ui.Spinner("Cloning template", func() error {
-		return cloneTemplate(config.Name)
-	}); err != nil {
-		return fmt.Errorf("failed to clone template: %w", err)
+	// The path is made absolute since some steps change directory into
+	// the project
+	projectPath, err := filepath.Abs(config.Name)
+	if err != nil {
+		return err
 	}
-	ui.Success("Template cloned")
 
-	// Replace module name in all files
-	if err := ui.Spinner("Configuring module", func() error {
-		return replaceModuleName(config.Name, moduleName)
-	}); err != nil {
-		return fmt.Errorf("failed to configure project: %w", err)
-	}
-	ui.Success("Module configured")
+	return j.run(
+		// Copy template. Removing the project directory is the last undo
+		// and catches anything the later steps' own undo actions miss.
+		func() error {
+			j.record("remove "+config.Name, func() error {
+				return os.RemoveAll(projectPath)
+			})
+			var fromCache bool
+			if err := ui.Spinner("Fetching template", func() error {
+				var err error
+				fromCache, err = installTemplate(source, config.Name)
+				return err
+			}); err != nil {
+				return fmt.Errorf("failed to fetch template: %w", err)
+			}
+			if fromCache {
+				ui.Warning("Template could not be fetched, using cached copy")
+			}
+			ui.Success("Template copied")
+			return nil
+		},
 
-	// Remove template git history and initialize new repo
-	if err := ui.Spinner("Initializing Git", func() error {
-		return reinitGitRepo(config.Name)
-	}); err != nil {
-		return fmt.Errorf("failed to initialize git: %w", err)
-	}
-	ui.Success("Git initialized")
+		// Replace module name in all files
+		func() error {
+			var files []string
+			if err := walkRenderFiles(projectPath, func(path string) error {
+				files = append(files, path)
+				return nil
+			}); err != nil {
+				return fmt.Errorf("failed to configure project: %w", err)
+			}
+			undo, err := restoreFiles(files...)
+			if err != nil {
+				return fmt.Errorf("failed to configure project: %w", err)
+			}
+			j.record("restore module placeholders", undo)
 
-	// Create default migrations
-	if err := createDefaultMigrations(config.Name); err != nil {
-		return fmt.Errorf("failed to create migrations: %w", err)
-	}
-	ui.Success("Migrations created")
+			var changed []string
+			if err := ui.Spinner("Configuring module", func() error {
+				var err error
+				changed, err = replaceModuleName(config.Name, moduleName, frameworkVersion(source))
+				return err
+			}); err != nil {
+				return fmt.Errorf("failed to configure project: %w", err)
+			}
+			ui.Success(fmt.Sprintf("Module configured (%d files updated)", len(changed)))
+			return nil
+		},
 
-	// Create proper .env.example with database config
-	if err := createEnvFiles(config); err != nil {
-		return fmt.Errorf("failed to create env files: %w", err)
-	}
-	ui.Success("Environment configured")
+		// Remove template git history and initialize new repo. The
+		// template's history is not worth keeping, so undo only removes
+		// the new repository.
+		func() error {
+			gitDir := filepath.Join(projectPath, ".git")
+			j.record("remove git repository", func() error {
+				return os.RemoveAll(gitDir)
+			})
+			if err := ui.Spinner("Initializing Git", func() error {
+				return reinitGitRepo(config.Name)
+			}); err != nil {
+				return fmt.Errorf("failed to initialize git: %w", err)
+			}
+			ui.Success("Git initialized")
+			return nil
+		},
 
-	// Setup hot reload
-	if err := setupTemplatesAndHotReload(config.Name); err != nil {
-		return fmt.Errorf("failed to setup templates: %w", err)
-	}
-	ui.Success("Hot reload configured")
+		// Create default migrations
+		func() error {
+			j.record("remove default migrations", removeAdded(filepath.Join(projectPath, "database", "migrations")))
+			if err := createDefaultMigrations(config.Name); err != nil {
+				return fmt.Errorf("failed to create migrations: %w", err)
+			}
+			ui.Success("Migrations created")
+			return nil
+		},
 
-	// Install dependencies
-	ui.Info("Installing dependencies")
-	if err := installDependencies(config.Name); err != nil {
-		return fmt.Errorf("failed to install dependencies: %w", err)
-	}
+		// Create proper .env.example with database config
+		func() error {
+			undo, err := restoreFiles(filepath.Join(projectPath, ".env"))
+			if err != nil {
+				return fmt.Errorf("failed to create env files: %w", err)
+			}
+			j.record("remove .env", undo)
+			if err := createEnvFiles(config); err != nil {
+				return fmt.Errorf("failed to create env files: %w", err)
+			}
+			ui.Success("Environment configured")
+			return nil
+		},
 
-	// Run migrations
-	ui.Newline()
-	ui.Info("Running migrations...")
-	if err := runMigrations(config.Name); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
-	ui.Success("Database ready")
+		// Setup hot reload
+		func() error {
+			if err := setupTemplatesAndHotReload(config.Name); err != nil {
+				return fmt.Errorf("failed to setup templates: %w", err)
+			}
+			ui.Success("Hot reload configured")
+			return nil
+		},
 
-	return nil
-}
+		// Install dependencies. Undo restores go.mod and go.sum, removes
+		// node_modules and lockfiles, and removes air if this installed it.
+		func() error {
+			undo, err := restoreFiles(filepath.Join(projectPath, "go.mod"), filepath.Join(projectPath, "go.sum"))
+			if err != nil {
+				return fmt.Errorf("failed to install dependencies: %w", err)
+			}
+			j.record("restore go.mod and go.sum", undo)
+			j.record("remove installed JS dependencies", removeAdded(projectPath))
+			if air := goInstalledPath("air"); air != "" {
+				if _, err := os.Stat(air); os.IsNotExist(err) {
+					j.record("remove "+air, func() error {
+						if err := os.Remove(air); err != nil && !os.IsNotExist(err) {
+							return err
+						}
+						return nil
+					})
+				}
+			}
 
-// cloneTemplate clones the velocity-template
-func cloneTemplate(projectName string) error {
-	// Use git clone directly (gh repo clone can use stale cache)
-	cmd := exec.Command("git", "clone", "--depth=1", "git@github.com:velocitykode/velocity-template.git", projectName)
-	if err := cmd.Run(); err != nil {
-		// Try HTTPS fallback
-		cmd = exec.Command("git", "clone", "--depth=1", "https://github.com/velocitykode/velocity-template.git", projectName)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to clone template: %w", err)
-		}
-	}
-	return nil
+			ui.Info("Installing dependencies")
+			if err := installDependencies(config.Name); err != nil {
+				return fmt.Errorf("failed to install dependencies: %w", err)
+			}
+			return nil
+		},
+
+		// Run migrations. Undo removes the runner and a SQLite database
+		// file; tables created on a database server are left in place.
+		func() error {
+			j.record("remove migration runner", removeAdded(projectPath))
+			j.record("remove SQLite database", removeAdded(filepath.Join(projectPath, "database")))
+
+			ui.Newline()
+			ui.Info("Running migrations...")
+			if err := runMigrations(config.Name); err != nil {
+				return fmt.Errorf("failed to run migrations: %w", err)
+			}
+			ui.Success("Database ready")
+			return nil
+		},
+	)
 }
 
-// replaceModuleName replaces {{MODULE_NAME}} in all files
-func replaceModuleName(projectPath, moduleName string) error {
-	// Get absolute path
+// replaceModuleName replaces {{MODULE_NAME}} in the project's Go, go.mod
+// and package files, pins the framework to velocityVersion when set and
+// returns the files it changed
+func replaceModuleName(projectPath, moduleName, velocityVersion string) ([]string, error) {
 	absPath, err := filepath.Abs(projectPath)
 	if err != nil {
-		return fmt.Errorf("abs path: %w", err)
+		return nil, fmt.Errorf("abs path: %w", err)
 	}
 
-	// Use find and sed to replace in all Go files
-	cmd := exec.Command("sh", "-c",
-		fmt.Sprintf("cd '%s' && find . -name '*.go' -type f -exec sed -i '' 's|{{MODULE_NAME}}|%s|g' {} +", absPath, moduleName))
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("replace go files: %w: %s", err, string(output))
+	changed, err := renderPlaceholders(absPath, Placeholders{"{{MODULE_NAME}}": moduleName})
+	if err != nil {
+		return nil, fmt.Errorf("replace module name: %w", err)
 	}
 
-	// Replace in go.mod
-	cmd = exec.Command("sh", "-c",
-		fmt.Sprintf("cd '%s' && sed -i '' 's|{{MODULE_NAME}}|%s|g' go.mod", absPath, moduleName))
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("replace go.mod: %w: %s", err, string(output))
+	// Remove the template's local replace directive
+	dropped, err := dropReplaceDirectives(filepath.Join(absPath, "go.mod"), "github.com/velocitykode/velocity")
+	if err != nil {
+		return nil, fmt.Errorf("update go.mod: %w", err)
 	}
-
-	// Replace in package.json
-	cmd = exec.Command("sh", "-c",
-		fmt.Sprintf("cd '%s' && sed -i '' 's|{{MODULE_NAME}}|%s|g' package.json", absPath, moduleName))
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("replace package.json: %w: %s", err, string(output))
+	if dropped && !containsString(changed, "go.mod") {
+		changed = append(changed, "go.mod")
 	}
 
-	// Replace in package-lock.json (if exists)
-	cmd = exec.Command("sh", "-c",
-		fmt.Sprintf("cd '%s' && [ -f package-lock.json ] && sed -i '' 's|{{MODULE_NAME}}|%s|g' package-lock.json || true", absPath, moduleName))
-	cmd.Run() // Ignore error - file may not exist
-
-	// Remove replace directive and fetch pinned version
-	cmd = exec.Command("sh", "-c",
-		fmt.Sprintf("cd '%s' && sed -i '' '/^replace github.com\\/velocitykode\\/velocity/d' go.mod", absPath))
-	if err := cmd.Run(); err != nil {
-		return err
+	if velocityVersion == "" {
+		return changed, nil
 	}
 
-	// Set pinned version of velocity framework (fetched from GitHub releases)
-	velocityVersion := getLatestVelocityVersion()
-	cmd = exec.Command("go", "mod", "edit", fmt.Sprintf("-require=github.com/velocitykode/velocity@%s", velocityVersion))
+	cmd := exec.Command("go", "mod", "edit", fmt.Sprintf("-require=github.com/velocitykode/velocity@%s", velocityVersion))
 	cmd.Dir = absPath
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set velocity framework version: %w", err)
+		return nil, fmt.Errorf("failed to set velocity framework version: %w", err)
 	}
 
-	return nil
+	return changed, nil
+}
+
+// frameworkVersion returns the framework version to pin a new project to.
+// Only the latest official template is pinned to the latest release;
+// custom and pinned templates keep the version their go.mod requires,
+// which also avoids the GitHub API on offline machines.
+func frameworkVersion(source templateSource) string {
+	if !pinsLatestFramework(source) {
+		return ""
+	}
+	return getLatestVelocityVersion()
+}
+
+// pinsLatestFramework reports whether a new project from source is pinned
+// to the latest framework release
+func pinsLatestFramework(source templateSource) bool {
+	return source.isDefault() && source.Ref == ""
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 // reinitGitRepo removes template git history and creates new repo
@@ -345,24 +489,25 @@ func validateProjectName(name string) error {
 	return nil
 }
 
+// projectDirectories is the directory structure init creates
+var projectDirectories = []string{
+	"app/http/controllers",
+	"app/http/middleware",
+	"app/models",
+	"bootstrap",
+	"cmd/velocity",
+	"config",
+	"database/migrations",
+	"database/factories",
+	"public",
+	"resources/views",
+	"routes",
+	"storage/logs",
+	"tests",
+}
+
 func createDirectoryStructure(projectPath string) error {
-	directories := []string{
-		"app/http/controllers",
-		"app/http/middleware",
-		"app/models",
-		"bootstrap",
-		"cmd/velocity",
-		"config",
-		"database/migrations",
-		"database/factories",
-		"public",
-		"resources/views",
-		"routes",
-		"storage/logs",
-		"tests",
-	}
-
-	for _, dir := range directories {
+	for _, dir := range projectDirectories {
 		path := filepath.Join(projectPath, dir)
 		if err := os.MkdirAll(path, 0755); err != nil {
 			return err
@@ -392,10 +537,9 @@ func initGoModule(config ProjectConfig) error {
 	ui.Step("Configuring dependencies...")
 
 	// Check if local Velocity exists and use replace directive
-	velocityPath := "/Users/ali/code/velocity"
-	if _, err := os.Stat(velocityPath); err == nil {
+	if hasLocalVelocity() {
 		// Add replace directive for local development
-		cmd = exec.Command("go", "mod", "edit", "-replace", "github.com/velocitykode/velocity="+velocityPath)
+		cmd = exec.Command("go", "mod", "edit", "-replace", "github.com/velocitykode/velocity="+localVelocityPath)
 		cmd.Run()
 		ui.Info("Using local Velocity framework")
 	} else {
@@ -480,9 +624,8 @@ func createEnvFiles(config ProjectConfig) error {
 		return err
 	}
 
-	// Copy .env.example to .env
-	cmd := exec.Command("cp", filepath.Join(absPath, ".env.example"), filepath.Join(absPath, ".env"))
-	if err := cmd.Run(); err != nil {
+	content, err := os.ReadFile(filepath.Join(absPath, ".env.example"))
+	if err != nil {
 		return err
 	}
 
@@ -492,10 +635,8 @@ func createEnvFiles(config ProjectConfig) error {
 		return err
 	}
 
-	// Replace crypto key in .env using sed
-	cmd = exec.Command("sh", "-c",
-		fmt.Sprintf("cd '%s' && sed -i '' 's|^CRYPTO_KEY=.*|CRYPTO_KEY=base64:%s|' .env", absPath, newKey))
-	return cmd.Run()
+	content = setEnvValue(content, "CRYPTO_KEY", "base64:"+newKey)
+	return os.WriteFile(filepath.Join(absPath, ".env"), content, 0644)
 }
 
 // generateCryptoKey generates a new 32-byte base64 encoded key
@@ -507,6 +648,13 @@ func generateCryptoKey() (string, error) {
 	return base64.StdEncoding.EncodeToString(key), nil
 }
 
+// defaultMigrationFiles are the migrations every new project starts with
+var defaultMigrationFiles = []string{
+	"0001_01_01_000000_create_users_table.go",
+	"0001_01_01_000001_create_cache_table.go",
+	"0001_01_01_000002_create_jobs_table.go",
+}
+
 // createDefaultMigrations creates the 3 default migration files
 func createDefaultMigrations(projectPath string) error {
 	absPath, err := filepath.Abs(projectPath)
@@ -613,9 +761,9 @@ func init() {
 
 	// Write migration files
 	migrations := map[string]string{
-		"0001_01_01_000000_create_users_table.go": usersTable,
-		"0001_01_01_000001_create_cache_table.go": cacheTable,
-		"0001_01_01_000002_create_jobs_table.go":  jobsTable,
+		defaultMigrationFiles[0]: usersTable,
+		defaultMigrationFiles[1]: cacheTable,
+		defaultMigrationFiles[2]: jobsTable,
 	}
 
 	for filename, content := range migrations {
@@ -762,7 +910,9 @@ func getProjectModuleName() (string, error) {
 	return "", fmt.Errorf("module name not found in go.mod")
 }
 
-// StartDevServers starts npm run dev and go run main.go in background
+// StartDevServers runs `velocity serve` in the new project in the
+// foreground. serve supervises the frontend dev server next to the app
+// and stops both on Ctrl-C.
 func StartDevServers(projectPath string) {
 	absPath, err := filepath.Abs(projectPath)
 	if err != nil {
@@ -770,24 +920,6 @@ func StartDevServers(projectPath string) {
 		return
 	}
 
-	// Start npm run dev in background (detached)
-	npmCmd := exec.Command("npm", "run", "dev")
-	npmCmd.Dir = absPath
-	npmCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	if err := npmCmd.Start(); err != nil {
-		ui.Error(fmt.Sprintf("Failed to start npm: %v", err))
-		return
-	}
-
-	// Start air for hot reloading (detached)
-	goCmd := exec.Command("air")
-	goCmd.Dir = absPath
-	goCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	if err := goCmd.Start(); err != nil {
-		ui.Error(fmt.Sprintf("Failed to start Go server: %v", err))
-		return
-	}
-
 	// Show URLs
 	ui.Step(fmt.Sprintf("cd %s", projectPath))
 	ui.KeyValue("Vite", ui.Highlight("http://localhost:5173"))
@@ -795,7 +927,22 @@ func StartDevServers(projectPath string) {
 
 	ui.Newline()
 	ui.Success("Build something great!")
+	ui.Muted("Press Ctrl-C to stop the development servers")
 	ui.Newline()
+
+	if err := os.Chdir(absPath); err != nil {
+		ui.Error(fmt.Sprintf("Failed to enter project: %v", err))
+		return
+	}
+
+	// Ctrl-C reaches serve too; wait for it to shut down instead of exiting
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	if err := delegator.Delegate([]string{"serve"}); err != nil {
+		ui.Error(fmt.Sprintf("Development servers stopped: %v", err))
+	}
 }
 
 func setupTemplatesAndHotReload(projectPath string) error {
@@ -803,6 +950,39 @@ func setupTemplatesAndHotReload(projectPath string) error {
 	return nil
 }
 
+// localVelocityPath is a framework checkout used instead of the released
+// module during framework development
+const localVelocityPath = "/Users/ali/code/velocity"
+
+// hasLocalVelocity reports whether the local framework checkout exists
+func hasLocalVelocity() bool {
+	_, err := os.Stat(localVelocityPath)
+	return err == nil
+}
+
+// dependency is a Go module added for a project feature
+type dependency struct {
+	Module string
+	Label  string
+}
+
+// featureDependencies returns the driver modules the config needs
+func featureDependencies(config ProjectConfig) []dependency {
+	var deps []dependency
+	switch config.Database {
+	case "postgres":
+		deps = append(deps, dependency{"github.com/lib/pq", "PostgreSQL driver"})
+	case "mysql":
+		deps = append(deps, dependency{"github.com/go-sql-driver/mysql", "MySQL driver"})
+	case "sqlite":
+		deps = append(deps, dependency{"github.com/mattn/go-sqlite3", "SQLite driver"})
+	}
+	if config.Cache == "redis" {
+		deps = append(deps, dependency{"github.com/redis/go-redis/v9", "Redis client"})
+	}
+	return deps
+}
+
 func addVelocityDependencies(config ProjectConfig, projectPath string) error {
 	// Change to project directory
 	originalDir, _ := os.Getwd()
@@ -812,10 +992,9 @@ func addVelocityDependencies(config ProjectConfig, projectPath string) error {
 	ui.Step("Configuring dependencies...")
 
 	// Check if local Velocity exists and use replace directive
-	velocityPath := "/Users/ali/code/velocity"
-	if _, err := os.Stat(velocityPath); err == nil {
+	if hasLocalVelocity() {
 		// Add replace directive for local development
-		cmd := exec.Command("go", "mod", "edit", "-replace", "github.com/velocitykode/velocity="+velocityPath)
+		cmd := exec.Command("go", "mod", "edit", "-replace", "github.com/velocitykode/velocity="+localVelocityPath)
 		cmd.Run()
 		ui.Info("Using local Velocity framework")
 	} else {
@@ -827,20 +1006,9 @@ func addVelocityDependencies(config ProjectConfig, projectPath string) error {
 	}
 
 	// Add other dependencies based on features
-	if config.Database == "postgres" {
-		ui.Info("PostgreSQL driver")
-		exec.Command("go", "get", "github.com/lib/pq").Run()
-	} else if config.Database == "mysql" {
-		ui.Info("MySQL driver")
-		exec.Command("go", "get", "github.com/go-sql-driver/mysql").Run()
-	} else if config.Database == "sqlite" {
-		ui.Info("SQLite driver")
-		exec.Command("go", "get", "github.com/mattn/go-sqlite3").Run()
-	}
-
-	if config.Cache == "redis" {
-		ui.Info("Redis client")
-		exec.Command("go", "get", "github.com/redis/go-redis/v9").Run()
+	for _, dep := range featureDependencies(config) {
+		ui.Info(dep.Label)
+		exec.Command("go", "get", dep.Module).Run()
 	}
 
 	// Run go mod tidy
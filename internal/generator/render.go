@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Placeholders maps template placeholders such as {{MODULE_NAME}} to the
+// values they are replaced with
+type Placeholders map[string]string
+
+// renderPatterns are the file names placeholders are substituted in
+var renderPatterns = []string{"go.mod", "*.go", "package.json", "package-lock.json"}
+
+// renderSkipDirs are never descended into while rendering
+var renderSkipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// renderPlaceholders replaces every placeholder in the files under root
+// that match renderPatterns. It returns the changed files relative to
+// root, sorted.
+func renderPlaceholders(root string, values Placeholders) ([]string, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys)*2)
+	for _, key := range keys {
+		pairs = append(pairs, key, values[key])
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	var changed []string
+	err := walkRenderFiles(root, func(path string) error {
+		updated, err := renderFile(path, replacer)
+		if err != nil {
+			return err
+		}
+		if updated {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			changed = append(changed, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// walkRenderFiles calls fn with the path of every file under root that
+// placeholders are rendered in
+func walkRenderFiles(root string, fn func(path string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && renderSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matchesRenderPattern(d.Name()) {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// matchesRenderPattern reports whether a file name matches renderPatterns
+func matchesRenderPattern(name string) bool {
+	for _, pattern := range renderPatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// renderFile applies replacer to a file in place, keeping its permissions.
+// It reports whether the content changed.
+func renderFile(path string, replacer *strings.Replacer) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	rendered := []byte(replacer.Replace(string(content)))
+	if bytes.Equal(rendered, content) {
+		return false, nil
+	}
+	return true, os.WriteFile(path, rendered, info.Mode().Perm())
+}
+
+// dropReplaceDirectives removes every replace directive for modulePath
+// from the go.mod file at path. It reports whether the file changed.
+func dropReplaceDirectives(path, modulePath string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	file, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for _, r := range file.Replace {
+		if r.Old.Path == modulePath {
+			if err := file.DropReplace(r.Old.Path, r.Old.Version); err != nil {
+				return false, err
+			}
+			found = true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	file.Cleanup()
+	formatted, err := file.Format()
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, formatted, 0644)
+}
+
+// setEnvValue replaces the value of key in env file content. Keys that
+// are not present are appended.
+func setEnvValue(content []byte, key, value string) []byte {
+	line := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `=.*$`)
+	entry := []byte(key + "=" + value)
+	if line.Match(content) {
+		return line.ReplaceAllLiteral(content, entry)
+	}
+
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	return append(append(content, entry...), '\n')
+}
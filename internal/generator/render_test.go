@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderPlaceholders(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestFile(t, filepath.Join(root, "go.mod"), "module {{MODULE_NAME}}\n")
+	writeTestFile(t, filepath.Join(root, "main.go"), "import _ \"{{MODULE_NAME}}/routes\"\n")
+	writeTestFile(t, filepath.Join(root, "app", "models", "user.go"), "package models\n")
+	writeTestFile(t, filepath.Join(root, "package.json"), `{"name": "{{MODULE_NAME}}"}`)
+	writeTestFile(t, filepath.Join(root, "README.md"), "# {{MODULE_NAME}}\n")
+	writeTestFile(t, filepath.Join(root, "node_modules", "pkg", "index.go"), "{{MODULE_NAME}}")
+
+	changed, err := renderPlaceholders(root, Placeholders{"{{MODULE_NAME}}": "example.com/blog"})
+	if err != nil {
+		t.Fatalf("renderPlaceholders() error = %v", err)
+	}
+
+	expected := []string{"go.mod", "main.go", "package.json"}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("renderPlaceholders() changed = %v, want %v", changed, expected)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(root, "main.go"))
+	if string(content) != "import _ \"example.com/blog/routes\"\n" {
+		t.Errorf("main.go not rendered: %s", content)
+	}
+
+	content, _ = os.ReadFile(filepath.Join(root, "README.md"))
+	if !strings.Contains(string(content), "{{MODULE_NAME}}") {
+		t.Error("Files outside the render patterns should be left alone")
+	}
+
+	content, _ = os.ReadFile(filepath.Join(root, "node_modules", "pkg", "index.go"))
+	if !strings.Contains(string(content), "{{MODULE_NAME}}") {
+		t.Error("node_modules should be skipped")
+	}
+}
+
+func TestRenderPlaceholders_MultipleValues(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "config.go"), "{{APP_NAME}} {{MODULE_NAME}} {{APP_NAME}}")
+
+	_, err := renderPlaceholders(root, Placeholders{
+		"{{MODULE_NAME}}": "blog",
+		"{{APP_NAME}}":    "Blog",
+	})
+	if err != nil {
+		t.Fatalf("renderPlaceholders() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(root, "config.go"))
+	if string(content) != "Blog blog Blog" {
+		t.Errorf("Unexpected content: %s", content)
+	}
+}
+
+func TestRenderPlaceholders_KeepsPermissions(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "run.go")
+	writeTestFile(t, path, "{{MODULE_NAME}}")
+	os.Chmod(path, 0600)
+
+	if _, err := renderPlaceholders(root, Placeholders{"{{MODULE_NAME}}": "blog"}); err != nil {
+		t.Fatalf("renderPlaceholders() error = %v", err)
+	}
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("File mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestDropReplaceDirectives(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "go.mod")
+	writeTestFile(t, path, `module blog
+
+go 1.25
+
+require github.com/velocitykode/velocity v0.0.3
+
+replace github.com/velocitykode/velocity => ../velocity
+
+replace example.com/other => ../other
+`)
+
+	dropped, err := dropReplaceDirectives(path, "github.com/velocitykode/velocity")
+	if err != nil {
+		t.Fatalf("dropReplaceDirectives() error = %v", err)
+	}
+	if !dropped {
+		t.Error("dropReplaceDirectives() should report the change")
+	}
+
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "=> ../velocity") {
+		t.Errorf("Replace directive not removed:\n%s", content)
+	}
+	if !strings.Contains(string(content), "example.com/other => ../other") {
+		t.Errorf("Unrelated replace directive removed:\n%s", content)
+	}
+
+	dropped, _ = dropReplaceDirectives(path, "github.com/velocitykode/velocity")
+	if dropped {
+		t.Error("dropReplaceDirectives() should report no change the second time")
+	}
+}
+
+func TestSetEnvValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"replaces", "APP_NAME=blog\nCRYPTO_KEY=\nDB=x\n", "APP_NAME=blog\nCRYPTO_KEY=base64:abc\nDB=x\n"},
+		{"appends", "APP_NAME=blog", "APP_NAME=blog\nCRYPTO_KEY=base64:abc\n"},
+		{"ignores similar keys", "MY_CRYPTO_KEY=old\n", "MY_CRYPTO_KEY=old\nCRYPTO_KEY=base64:abc\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(setEnvValue([]byte(tt.content), "CRYPTO_KEY", "base64:abc"))
+			if got != tt.expected {
+				t.Errorf("setEnvValue() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCreateEnvFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	writeTestFile(t, filepath.Join("blog", ".env.example"), "APP_NAME=blog\nCRYPTO_KEY=\n")
+
+	if err := createEnvFiles(ProjectConfig{Name: "blog"}); err != nil {
+		t.Fatalf("createEnvFiles() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join("blog", ".env"))
+	if err != nil {
+		t.Fatal(".env should be created")
+	}
+	if !strings.Contains(string(content), "CRYPTO_KEY=base64:") || strings.Contains(string(content), "CRYPTO_KEY=\n") {
+		t.Errorf("CRYPTO_KEY not set: %s", content)
+	}
+
+	example, _ := os.ReadFile(filepath.Join("blog", ".env.example"))
+	if string(example) != "APP_NAME=blog\nCRYPTO_KEY=\n" {
+		t.Error(".env.example should not be modified")
+	}
+}
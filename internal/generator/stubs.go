@@ -45,46 +45,48 @@ func copyStubFileWithConfig(stubName, destPath string, config interface{}) error
 	return os.WriteFile(destPath, content, 0644)
 }
 
-// generateFilesFromStubs copies all necessary stub files to the project
-func generateFilesFromStubs(config ProjectConfig) error {
-	// Copy main.go with template processing
-	if err := copyStubFileWithConfig("main.go.stub", filepath.Join(config.Name, "main.go"), config); err != nil {
-		return err
-	}
-
-	// Copy home controller
-	if err := copyStubFileWithConfig("app/controllers/home_controller.go.stub", filepath.Join(config.Name, "app", "controllers", "home_controller.go"), config); err != nil {
-		return err
-	}
+// stubFile is an application file init generates from a stub
+type stubFile struct {
+	Stub     string
+	Path     string // Relative to the project root
+	Template bool   // Whether the stub is rendered with the project config
+}
 
-	// Copy middleware
-	if err := copyStubFile("app/middleware/middleware.go.stub", filepath.Join(config.Name, "app", "middleware", "middleware.go")); err != nil {
-		return err
+// initStubFiles returns the stub files init writes for config
+func initStubFiles(config ProjectConfig) []stubFile {
+	files := []stubFile{
+		{"main.go.stub", "main.go", true},
+		{"app/controllers/home_controller.go.stub", "app/controllers/home_controller.go", true},
+		{"app/middleware/middleware.go.stub", "app/middleware/middleware.go", false},
+		{"routes/web.go.stub", "routes/web.go", true},
+		{"config/config.go.stub", "config/config.go", true},
 	}
 
-	// Copy web routes with template processing
-	if err := copyStubFileWithConfig("routes/web.go.stub", filepath.Join(config.Name, "routes", "web.go"), config); err != nil {
-		return err
+	// API routes if API mode
+	if config.API {
+		files = append(files, stubFile{"routes/api.go.stub", "routes/api.go", true})
 	}
 
-	// Copy config with template processing
-	if err := copyStubFileWithConfig("config/config.go.stub", filepath.Join(config.Name, "config", "config.go"), config); err != nil {
-		return err
+	// Auth files if auth is enabled
+	if config.Auth {
+		files = append(files,
+			stubFile{"app/controllers/auth_controller.go.stub", "app/controllers/auth_controller.go", false},
+			stubFile{"app/middleware/auth.go.stub", "app/middleware/auth.go", false},
+		)
 	}
 
-	// Copy API routes if API mode
-	if config.API {
-		if err := copyStubFileWithConfig("routes/api.go.stub", filepath.Join(config.Name, "routes", "api.go"), config); err != nil {
-			return err
-		}
-	}
+	return files
+}
 
-	// Copy auth files if auth is enabled
-	if config.Auth {
-		if err := copyStubFile("app/controllers/auth_controller.go.stub", filepath.Join(config.Name, "app", "controllers", "auth_controller.go")); err != nil {
-			return err
+// generateFilesFromStubs copies the application stubs into the project
+func generateFilesFromStubs(config ProjectConfig) error {
+	for _, file := range initStubFiles(config) {
+		destPath := filepath.Join(config.Name, filepath.FromSlash(file.Path))
+		var data interface{}
+		if file.Template {
+			data = config
 		}
-		if err := copyStubFile("app/middleware/auth.go.stub", filepath.Join(config.Name, "app", "middleware", "auth.go")); err != nil {
+		if err := copyStubFileWithConfig(file.Stub, destPath, data); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,405 @@
+package generator
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/velocitykode/velocity-cli/internal/config"
+)
+
+// Default template repository, tried over SSH first and then HTTPS
+var defaultTemplateURLs = []string{
+	"git@github.com:velocitykode/velocity-template.git",
+	"https://github.com/velocitykode/velocity-template.git",
+}
+
+// Template source kinds
+const (
+	templateGit     = "git"
+	templateDir     = "dir"
+	templateArchive = "archive"
+)
+
+// templateSource is where a new project's files come from
+type templateSource struct {
+	Kind     string
+	Location string // Directory, archive path or URL
+	Ref      string // Branch, tag or commit for git templates
+}
+
+// resolveTemplate works out the kind of a --template value. An empty value
+// selects the official template repository.
+func resolveTemplate(location, ref string) (templateSource, error) {
+	if location == "" {
+		return templateSource{Kind: templateGit, Ref: ref}, nil
+	}
+
+	isRemote := isRemoteURL(location)
+
+	if strings.HasSuffix(location, ".tar.gz") || strings.HasSuffix(location, ".tgz") {
+		if ref != "" {
+			return templateSource{}, fmt.Errorf("--ref cannot be used with an archive template")
+		}
+		if !isRemote {
+			if _, err := os.Stat(location); err != nil {
+				return templateSource{}, fmt.Errorf("template archive not found: %s", location)
+			}
+		}
+		return templateSource{Kind: templateArchive, Location: location}, nil
+	}
+
+	if info, err := os.Stat(location); err == nil && info.IsDir() {
+		if ref != "" {
+			return templateSource{}, fmt.Errorf("--ref cannot be used with a local template directory")
+		}
+		return templateSource{Kind: templateDir, Location: location}, nil
+	}
+
+	if isRemote || strings.HasPrefix(location, "git@") || strings.HasPrefix(location, "ssh://") ||
+		strings.HasPrefix(location, "file://") || strings.HasSuffix(location, ".git") {
+		return templateSource{Kind: templateGit, Location: location, Ref: ref}, nil
+	}
+
+	return templateSource{}, fmt.Errorf("template not found: %s (expected a directory, git URL or .tar.gz archive)", location)
+}
+
+// isRemoteURL reports whether location is an http(s) URL
+func isRemoteURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// isDefault reports whether the source is the official template
+func (s templateSource) isDefault() bool {
+	return s.Kind == templateGit && s.Location == ""
+}
+
+// urls returns the locations to try, in order
+func (s templateSource) urls() []string {
+	if s.isDefault() {
+		return defaultTemplateURLs
+	}
+	return []string{s.Location}
+}
+
+// cacheKey names the cache entry for a remote source
+func (s templateSource) cacheKey() string {
+	location := s.Location
+	if s.isDefault() {
+		location = defaultTemplateURLs[len(defaultTemplateURLs)-1]
+	}
+
+	name := strings.TrimSuffix(filepath.Base(strings.TrimSuffix(location, "/")), ".git")
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".tar.gz"), ".tgz")
+
+	sum := sha256.Sum256([]byte(location + "@" + s.Ref))
+	key := name + "-" + hex.EncodeToString(sum[:])[:12]
+	if s.Ref != "" {
+		key += "@" + strings.NewReplacer("/", "-", "\\", "-").Replace(s.Ref)
+	}
+	return key
+}
+
+// TemplateCacheDir returns the directory templates are cached in
+func TemplateCacheDir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+// installTemplate copies the template into dest. Remote templates are
+// fetched into the cache first; when fetching fails a cached copy is used
+// instead, which is reported through fromCache.
+func installTemplate(source templateSource, dest string) (fromCache bool, err error) {
+	switch source.Kind {
+	case templateDir:
+		return false, copyTemplateDir(source.Location, dest)
+	case templateArchive:
+		if !isRemoteURL(source.Location) {
+			return false, extractArchive(source.Location, dest)
+		}
+	}
+
+	cacheDir, err := TemplateCacheDir()
+	if err != nil {
+		return false, err
+	}
+	cached := filepath.Join(cacheDir, source.cacheKey())
+
+	if fetchErr := fetchTemplate(source, cacheDir, cached); fetchErr != nil {
+		if _, err := os.Stat(cached); err != nil {
+			return false, fetchErr
+		}
+		fromCache = true
+	}
+
+	return fromCache, copyTemplateDir(cached, dest)
+}
+
+// fetchTemplate downloads a remote template into a scratch directory and
+// moves it into place as the cached copy
+func fetchTemplate(source templateSource, cacheDir, cached string) error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(cacheDir, ".fetch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	checkout := filepath.Join(tmp, "template")
+	if source.Kind == templateArchive {
+		err = downloadArchive(source.Location, tmp, checkout)
+	} else {
+		err = cloneTemplateRepo(source, checkout)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Swap the fresh copy in; the old one is only removed once the new
+	// one is complete
+	old := cached + ".old"
+	os.RemoveAll(old)
+	if _, err := os.Stat(cached); err == nil {
+		if err := os.Rename(cached, old); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(checkout, cached); err != nil {
+		os.Rename(old, cached)
+		return err
+	}
+	os.RemoveAll(old)
+	return nil
+}
+
+// cloneTemplateRepo clones a git template at its ref without history
+func cloneTemplateRepo(source templateSource, dest string) error {
+	var lastErr error
+	for _, url := range source.urls() {
+		os.RemoveAll(dest)
+		if lastErr = gitClone(url, source.Ref, dest); lastErr == nil {
+			return os.RemoveAll(filepath.Join(dest, ".git"))
+		}
+	}
+	return lastErr
+}
+
+// gitClone clones url into dest. Branches and tags use a shallow clone;
+// commits need the full history to be checked out.
+func gitClone(url, ref, dest string) error {
+	args := []string{"clone", "--depth=1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, url, dest)
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ref == "" {
+		return fmt.Errorf("git clone %s: %w: %s", url, err, strings.TrimSpace(string(output)))
+	}
+
+	// --branch only accepts branches and tags, so retry for a commit
+	os.RemoveAll(dest)
+	if output, err := exec.Command("git", "clone", url, dest).CombinedOutput(); err != nil {
+		return fmt.Errorf("git clone %s: %w: %s", url, err, strings.TrimSpace(string(output)))
+	}
+	if output, err := exec.Command("git", "-C", dest, "checkout", ref).CombinedOutput(); err != nil {
+		return fmt.Errorf("git checkout %s: %w: %s", ref, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// downloadArchive fetches a .tar.gz template and extracts it into dest
+func downloadArchive(url, tmp, dest string) error {
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: %s", url, resp.Status)
+	}
+
+	archive := filepath.Join(tmp, "template.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return extractArchive(archive, dest)
+}
+
+// extractArchive unpacks a .tar.gz into dest. When every entry sits under
+// one top-level directory, as in release archives, that directory is
+// stripped.
+func extractArchive(archive, dest string) error {
+	prefix, err := archivePrefix(archive)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", archive, err)
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", archive, err)
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(hdr.Name, "./"), prefix)
+		if name == "" || name == ".git" || strings.HasPrefix(name, ".git/") {
+			continue
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry escapes the project: %s", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archivePrefix returns the single top-level directory shared by every
+// entry, with a trailing slash, or "" when there is none
+func archivePrefix(archive string) (string, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", archive, err)
+	}
+	defer gz.Close()
+
+	prefix := ""
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return prefix, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", archive, err)
+		}
+
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if name == "" {
+			continue
+		}
+		first, _, nested := strings.Cut(name, "/")
+		if !nested && hdr.Typeflag != tar.TypeDir {
+			return "", nil // A file at the top level
+		}
+		if prefix == "" {
+			prefix = first + "/"
+		} else if prefix != first+"/" {
+			return "", nil
+		}
+	}
+}
+
+// copyTemplateDir copies a template directory into dest, leaving out its
+// git metadata
+func copyTemplateDir(src, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		target := filepath.Join(dest, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, content, info.Mode().Perm())
+		}
+		return nil
+	})
+}
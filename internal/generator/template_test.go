@@ -0,0 +1,246 @@
+package generator
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestArchive(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func TestResolveTemplate(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "template.tar.gz")
+	os.WriteFile(archive, nil, 0644)
+
+	tests := []struct {
+		location string
+		ref      string
+		kind     string
+		wantErr  bool
+	}{
+		{"", "", templateGit, false},
+		{"", "v1.2.0", templateGit, false},
+		{dir, "", templateDir, false},
+		{dir, "v1.2.0", "", true},
+		{archive, "", templateArchive, false},
+		{archive, "main", "", true},
+		{"https://example.com/template.tar.gz", "", templateArchive, false},
+		{"git@github.com:acme/template.git", "v1.2.0", templateGit, false},
+		{"https://github.com/acme/template", "", templateGit, false},
+		{filepath.Join(dir, "missing"), "", "", true},
+		{filepath.Join(dir, "missing.tar.gz"), "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location+"@"+tt.ref, func(t *testing.T) {
+			source, err := resolveTemplate(tt.location, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && source.Kind != tt.kind {
+				t.Errorf("resolveTemplate() kind = %q, want %q", source.Kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestTemplateSource_CacheKey(t *testing.T) {
+	a := templateSource{Kind: templateGit, Location: "https://github.com/acme/template.git", Ref: "v1.0.0"}
+	b := templateSource{Kind: templateGit, Location: "https://github.com/acme/template.git", Ref: "v2.0.0"}
+
+	if a.cacheKey() == b.cacheKey() {
+		t.Error("Different refs should not share a cache entry")
+	}
+	if !strings.HasPrefix(a.cacheKey(), "template-") || !strings.HasSuffix(a.cacheKey(), "@v1.0.0") {
+		t.Errorf("Unexpected cache key: %s", a.cacheKey())
+	}
+
+	branch := templateSource{Kind: templateGit, Location: "https://github.com/acme/template.git", Ref: "feature/x"}
+	if strings.Contains(branch.cacheKey(), "/") {
+		t.Errorf("Cache key should be a single path element: %s", branch.cacheKey())
+	}
+}
+
+func TestFrameworkVersion_CustomTemplate(t *testing.T) {
+	custom := templateSource{Kind: templateDir, Location: "/templates/acme"}
+	if v := frameworkVersion(custom); v != "" {
+		t.Errorf("frameworkVersion() = %q, custom templates should keep their own version", v)
+	}
+
+	pinned := templateSource{Kind: templateGit, Ref: "v1.2.0"}
+	if v := frameworkVersion(pinned); v != "" {
+		t.Errorf("frameworkVersion() = %q, pinned templates should keep their own version", v)
+	}
+}
+
+func TestExtractArchive_StripsTopLevelDir(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "template.tar.gz")
+	writeTestArchive(t, archive, map[string]string{
+		"velocity-template-1.2.0/go.mod":         "module {{MODULE_NAME}}\n",
+		"velocity-template-1.2.0/routes/web.go":  "package routes\n",
+		"velocity-template-1.2.0/.git/HEAD":      "ref: main\n",
+		"velocity-template-1.2.0/public/app.css": "",
+	})
+
+	dest := filepath.Join(dir, "app")
+	if err := extractArchive(archive, dest); err != nil {
+		t.Fatalf("extractArchive() error = %v", err)
+	}
+
+	for _, path := range []string{"go.mod", "routes/web.go", "public/app.css"} {
+		if _, err := os.Stat(filepath.Join(dest, path)); err != nil {
+			t.Errorf("Expected %s to be extracted", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".git")); !os.IsNotExist(err) {
+		t.Error("Git metadata should not be extracted")
+	}
+}
+
+func TestExtractArchive_FlatLayout(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "template.tar.gz")
+	writeTestArchive(t, archive, map[string]string{
+		"go.mod":        "module {{MODULE_NAME}}\n",
+		"routes/web.go": "package routes\n",
+	})
+
+	dest := filepath.Join(dir, "app")
+	if err := extractArchive(archive, dest); err != nil {
+		t.Fatalf("extractArchive() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "routes", "web.go")); err != nil {
+		t.Error("Nested files should keep their directory")
+	}
+}
+
+func TestExtractArchive_RejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "template.tar.gz")
+	writeTestArchive(t, archive, map[string]string{
+		"go.mod":        "module x\n",
+		"../escaped.go": "package evil\n",
+	})
+
+	if err := extractArchive(archive, filepath.Join(dir, "app")); err == nil {
+		t.Error("extractArchive() should reject entries outside the destination")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped.go")); !os.IsNotExist(err) {
+		t.Error("Entry outside the destination should not be written")
+	}
+}
+
+func TestInstallTemplate_LocalDir(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "template")
+	writeTestFile(t, filepath.Join(src, "go.mod"), "module {{MODULE_NAME}}\n")
+	writeTestFile(t, filepath.Join(src, ".git", "HEAD"), "ref: main\n")
+
+	dest := filepath.Join(dir, "app")
+	fromCache, err := installTemplate(templateSource{Kind: templateDir, Location: src}, dest)
+	if err != nil || fromCache {
+		t.Fatalf("installTemplate() = %v, %v", fromCache, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dest, "go.mod")); err != nil {
+		t.Error("Template files should be copied")
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".git")); !os.IsNotExist(err) {
+		t.Error("Template git metadata should not be copied")
+	}
+}
+
+func TestInstallTemplate_GitRefAndCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Build a template repository with a tagged and an untagged commit
+	repo := filepath.Join(t.TempDir(), "template")
+	writeTestFile(t, filepath.Join(repo, "VERSION"), "1\n")
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-qm", "v1")
+	git("tag", "v1.0.0")
+	writeTestFile(t, filepath.Join(repo, "VERSION"), "2\n")
+	git("commit", "-qam", "v2")
+
+	source, err := resolveTemplate("file://"+repo, "v1.0.0")
+	if err != nil {
+		t.Fatalf("resolveTemplate() error = %v", err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "app")
+	fromCache, err := installTemplate(source, dest)
+	if err != nil || fromCache {
+		t.Fatalf("installTemplate() = %v, %v", fromCache, err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dest, "VERSION"))
+	if string(content) != "1\n" {
+		t.Errorf("Template should be checked out at the ref, got VERSION %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".git")); !os.IsNotExist(err) {
+		t.Error("Template git history should not be copied")
+	}
+
+	cacheDir, _ := TemplateCacheDir()
+	if _, err := os.Stat(filepath.Join(cacheDir, source.cacheKey(), "VERSION")); err != nil {
+		t.Errorf("Template should be cached under %s", cacheDir)
+	}
+
+	// With the repository gone the cached copy is used
+	os.RemoveAll(repo)
+	dest = filepath.Join(t.TempDir(), "offline")
+	fromCache, err = installTemplate(source, dest)
+	if err != nil || !fromCache {
+		t.Fatalf("installTemplate() offline = %v, %v, want cached copy", fromCache, err)
+	}
+	content, _ = os.ReadFile(filepath.Join(dest, "VERSION"))
+	if string(content) != "1\n" {
+		t.Errorf("Cached template has VERSION %q", content)
+	}
+}
+
+func TestInstallTemplate_NoCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	source := templateSource{Kind: templateGit, Location: "file://" + filepath.Join(t.TempDir(), "missing")}
+	if _, err := installTemplate(source, filepath.Join(t.TempDir(), "app")); err == nil {
+		t.Error("installTemplate() should fail when the template cannot be fetched and is not cached")
+	}
+}
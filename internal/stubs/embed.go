@@ -2,7 +2,7 @@ package stubs
 
 import "embed"
 
-//go:embed app/http/controllers/*.stub app/middleware/*.stub routes/*.stub config/*.stub main.go.stub
+//go:embed docker/*.stub app/events/*.stub app/http/controllers/*.stub app/http/requests/*.stub app/jobs/*.stub app/listeners/*.stub app/middleware/*.stub app/models/*.stub database/factories/*.stub database/migrations/*.stub routes/*.stub config/*.stub main.go.stub
 var FS embed.FS
 
 // Get returns the content of a stub file
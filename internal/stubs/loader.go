@@ -0,0 +1,201 @@
+package stubs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// ProjectDir is the directory inside a project whose stubs override the
+// embedded ones. Files mirror the embedded layout, e.g.
+// stubs/app/http/controllers/controller.go.stub.
+const ProjectDir = "stubs"
+
+// Variable documents a template variable passed to a stub
+type Variable struct {
+	Name        string
+	Description string
+}
+
+var (
+	packageVar = Variable{"Package", "Go package name, taken from the parent directory for nested names"}
+	nameVar    = Variable{"Name", "PascalCase type name without the artifact suffix"}
+
+	dockerVars = []Variable{
+		{"Name", "App name, the binary a local build writes"},
+		{"GoVersion", "Go version of the builder image, from go.mod"},
+		{"CGO", "True when dependencies need cgo"},
+		{"Database", "Database driver: postgres, mysql, sqlite or empty"},
+		{"Frontend", "Command that builds the frontend, empty without one"},
+		{"FrontendImage", "Image of the frontend stage, node or bun"},
+		{"Install", "Command that installs the frontend dependencies"},
+		{"Lockfile", "Frontend lockfile copied before installing, if any"},
+		{"Assets", "Directory the frontend build writes to"},
+		{"Dirs", "Project directories the app reads at runtime"},
+		{"Migrations", "True when cmd/velocity runs migrations before the server starts"},
+		{"Port", "Port the app listens on, from APP_PORT"},
+	}
+)
+
+// Variables lists the template variables each generator stub receives.
+// These are the stubs that can be published and overridden by a project.
+var Variables = map[string][]Variable{
+	"app/http/controllers/controller.go.stub": {
+		packageVar,
+		nameVar,
+		{"ControllerName", "Same as Name, kept for older stubs"},
+		{"Resource", "True when --resource was passed"},
+		{"API", "True when --api was passed"},
+	},
+	"app/middleware/make.go.stub":       {packageVar, nameVar},
+	"app/http/requests/request.go.stub": {packageVar, nameVar},
+	"app/jobs/job.go.stub":              {packageVar, nameVar},
+	"app/events/event.go.stub":          {packageVar, nameVar},
+	"app/listeners/listener.go.stub":    {packageVar, nameVar},
+	"app/models/model.go.stub": {
+		{"ModelName", "PascalCase model name"},
+		{"Table", "Pluralized snake_case table name"},
+	},
+	"database/factories/factory.go.stub": {
+		{"ModelName", "PascalCase model name"},
+		{"Table", "Pluralized snake_case table name"},
+	},
+	"docker/Dockerfile.stub":   dockerVars,
+	"docker/dockerignore.stub": dockerVars,
+	"database/migrations/migration.go.stub": {
+		{"Version", "Timestamp version in YYYYMMDDHHmmss format"},
+		{"Description", "Migration name with underscores replaced by spaces"},
+		{"Table", "Table guessed from the name or given by --create/--table"},
+		{"Create", "True when the migration creates Table"},
+	},
+}
+
+// Publishable returns the names of the stubs a project can override, sorted
+func Publishable() []string {
+	names := make([]string, 0, len(Variables))
+	for name := range Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns the content of a stub, preferring a project-local copy in
+// ProjectDir over the embedded one. Project stubs are validated before use.
+// The returned path is where the stub was read from.
+func Load(name string) ([]byte, string, error) {
+	localPath := filepath.Join(ProjectDir, filepath.FromSlash(name))
+	content, err := os.ReadFile(localPath)
+	if err == nil {
+		if err := Validate(name, content); err != nil {
+			return nil, localPath, fmt.Errorf("%s: %w", localPath, err)
+		}
+		return content, localPath, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, localPath, err
+	}
+
+	content, err = Get(name)
+	return content, "", err
+}
+
+// Validate checks that a stub parses as a template and only references
+// the variables documented for it in Variables
+func Validate(name string, content []byte) error {
+	tmpl, err := template.New(name).Parse(string(content))
+	if err != nil {
+		return err
+	}
+
+	vars, ok := Variables[name]
+	if !ok {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	var available []string
+	for _, v := range vars {
+		known[v.Name] = true
+		available = append(available, "."+v.Name)
+	}
+
+	var unknown []string
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			collectUnknownFields(t.Tree.Root, known, &unknown)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown template variable %s (available: %s)",
+			strings.Join(unknown, ", "), strings.Join(available, ", "))
+	}
+	return nil
+}
+
+// collectUnknownFields walks a template tree and records references to
+// top-level fields that are not known. Bodies of range and with blocks are
+// skipped because dot is rebound inside them.
+func collectUnknownFields(node parse.Node, known map[string]bool, unknown *[]string) {
+	if node == nil {
+		return
+	}
+
+	check := func(field string) {
+		if known[field] {
+			return
+		}
+		for _, u := range *unknown {
+			if u == "."+field {
+				return
+			}
+		}
+		*unknown = append(*unknown, "."+field)
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectUnknownFields(child, known, unknown)
+		}
+	case *parse.ActionNode:
+		collectUnknownFields(n.Pipe, known, unknown)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				collectUnknownFields(arg, known, unknown)
+			}
+		}
+	case *parse.FieldNode:
+		check(n.Ident[0])
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			check(n.Ident[1])
+		}
+	case *parse.ChainNode:
+		collectUnknownFields(n.Node, known, unknown)
+	case *parse.IfNode:
+		collectUnknownFields(n.Pipe, known, unknown)
+		collectUnknownFields(n.List, known, unknown)
+		collectUnknownFields(n.ElseList, known, unknown)
+	case *parse.RangeNode:
+		collectUnknownFields(n.Pipe, known, unknown)
+		collectUnknownFields(n.ElseList, known, unknown)
+	case *parse.WithNode:
+		collectUnknownFields(n.Pipe, known, unknown)
+		collectUnknownFields(n.ElseList, known, unknown)
+	case *parse.TemplateNode:
+		collectUnknownFields(n.Pipe, known, unknown)
+	}
+}
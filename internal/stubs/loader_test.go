@@ -0,0 +1,107 @@
+package stubs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPublishable_EmbeddedStubsValidate(t *testing.T) {
+	names := Publishable()
+	if len(names) != len(Variables) {
+		t.Fatalf("Publishable() returned %d stubs, want %d", len(names), len(Variables))
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			content, err := Get(name)
+			if err != nil {
+				t.Fatalf("Publishable stub %s is not embedded: %v", name, err)
+			}
+			if err := Validate(name, content); err != nil {
+				t.Errorf("Embedded stub uses undocumented variables: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	name := "app/jobs/job.go.stub"
+
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"package {{ .Package }}\ntype {{ .Name }} struct{}", ""},
+		{"{{ if .Name }}{{ $.Package }}{{ end }}", ""},
+		{"{{ range .Package }}{{ .Anything }}{{ end }}", ""},
+		{"package {{ .Pkg }}", "unknown template variable .Pkg"},
+		{"{{ if .Name }}{{ else }}{{ $.Table }}{{ end }}", "unknown template variable .Table"},
+		{"{{ .Name", "unclosed action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			err := Validate(name, []byte(tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_PrefersProjectStub(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	name := "app/events/event.go.stub"
+
+	content, path, err := Load(name)
+	if err != nil || path != "" {
+		t.Fatalf("Load() = %q, %v, want embedded stub", path, err)
+	}
+	embedded, _ := Get(name)
+	if string(content) != string(embedded) {
+		t.Error("Load() should return the embedded stub when no project stub exists")
+	}
+
+	localPath := filepath.Join(ProjectDir, "app", "events", "event.go.stub")
+	os.MkdirAll(filepath.Dir(localPath), 0755)
+	os.WriteFile(localPath, []byte("package {{ .Package }} // custom"), 0644)
+
+	content, path, err = Load(name)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if path != localPath || !strings.Contains(string(content), "// custom") {
+		t.Errorf("Load() = %q from %q, want project stub", content, path)
+	}
+}
+
+func TestLoad_InvalidProjectStub(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(originalDir)
+
+	localPath := filepath.Join(ProjectDir, "app", "events", "event.go.stub")
+	os.MkdirAll(filepath.Dir(localPath), 0755)
+	os.WriteFile(localPath, []byte("package {{ .Namespace }}"), 0644)
+
+	_, _, err := Load("app/events/event.go.stub")
+	if err == nil {
+		t.Fatal("Load() should reject a project stub with unknown variables")
+	}
+	if !strings.Contains(err.Error(), localPath) || !strings.Contains(err.Error(), ".Namespace") {
+		t.Errorf("Load() error should name the stub and variable, got %v", err)
+	}
+}
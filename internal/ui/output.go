@@ -56,6 +56,11 @@ func Error(message string) {
 	fmt.Printf("%s %s\n", crossSymbol, errorStyle.Render(message))
 }
 
+// ErrorText returns an error message styled like Error without printing it
+func ErrorText(message string) string {
+	return fmt.Sprintf("%s %s", crossSymbol, errorStyle.Render(message))
+}
+
 // Step prints a muted step message (indented)
 func Step(message string) {
 	fmt.Printf("  %s\n", mutedStyle.Render(message))
@@ -154,6 +159,72 @@ func TreeItemSkipped(prefix, label, reason string) {
 	fmt.Printf("  %s %s %s\n", mutedStyle.Render(prefix), mutedStyle.Render(label), warningStyle.Render("skipped ("+reason+")"))
 }
 
+// TreeNode is an entry printed by Tree
+type TreeNode struct {
+	Label    string
+	Note     string // Shown muted after the label
+	Children []TreeNode
+}
+
+// Tree prints root followed by its children as an indented tree
+func Tree(root string, children []TreeNode) {
+	fmt.Printf("  %s\n", primaryStyle.Render(root))
+	printTreeNodes(children, "")
+}
+
+func printTreeNodes(nodes []TreeNode, indent string) {
+	for i, node := range nodes {
+		branch, next := "├─", "│  "
+		if i == len(nodes)-1 {
+			branch, next = "└─", "   "
+		}
+
+		line := node.Label
+		if node.Note != "" {
+			line += " " + mutedStyle.Render(node.Note)
+		}
+		fmt.Printf("  %s %s\n", mutedStyle.Render(indent+branch), line)
+		printTreeNodes(node.Children, indent+next)
+	}
+}
+
+// Table prints rows as left-aligned columns under a muted header row
+func Table(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = lipgloss.Width(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && lipgloss.Width(cell) > widths[i] {
+				widths[i] = lipgloss.Width(cell)
+			}
+		}
+	}
+
+	pad := func(cell string, width int) string {
+		return cell + strings.Repeat(" ", width-lipgloss.Width(cell))
+	}
+
+	var header []string
+	for i, h := range headers {
+		header = append(header, mutedStyle.Render(pad(h, widths[i])))
+	}
+	fmt.Printf("  %s\n", strings.TrimRight(strings.Join(header, "  "), " "))
+
+	for _, row := range rows {
+		var cells []string
+		for i := range headers {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			cells = append(cells, pad(cell, widths[i]))
+		}
+		fmt.Printf("  %s\n", strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+}
+
 // ClearLines clears n lines above cursor
 func ClearLines(n int) {
 	for i := 0; i < n; i++ {
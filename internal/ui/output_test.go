@@ -2,6 +2,8 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"testing"
 )
@@ -63,3 +65,58 @@ func TestSpinnerWithError(t *testing.T) {
 		t.Errorf("Spinner should return error from action, got: %v", err)
 	}
 }
+
+func TestTable(t *testing.T) {
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+
+	Table([]string{"Name", "State"}, [][]string{
+		{"create_users_table", "Applied"},
+		{"short"},
+	})
+
+	w.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(r)
+
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Table should print header and 2 rows, got %d lines", len(lines))
+	}
+	if !strings.Contains(lines[1], "create_users_table  Applied") {
+		t.Errorf("Table row not aligned: %q", lines[1])
+	}
+	if strings.HasSuffix(lines[2], " ") {
+		t.Errorf("Table row should not have trailing spaces: %q", lines[2])
+	}
+}
+
+func TestTree(t *testing.T) {
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+
+	Tree("app", []TreeNode{
+		{Label: "Files", Children: []TreeNode{
+			{Label: "main.go", Note: "create"},
+			{Label: "go.mod", Note: "skip"},
+		}},
+		{Label: "Commands", Children: []TreeNode{{Label: "go mod tidy"}}},
+	})
+
+	w.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(r)
+
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Tree should print root and 5 nodes, got %d lines:\n%s", len(lines), out)
+	}
+	if !strings.Contains(lines[2], "│  ├─") || !strings.Contains(lines[2], "main.go") {
+		t.Errorf("Nested node should continue the parent branch: %q", lines[2])
+	}
+	if !strings.Contains(lines[5], "   └─") || !strings.Contains(lines[5], "go mod tidy") {
+		t.Errorf("Last branch should not draw a continuation line: %q", lines[5])
+	}
+}